@@ -0,0 +1,60 @@
+// +build !headless
+
+package main
+
+import (
+    "github.com/hajimehoshi/ebiten/audio"
+)
+
+// NOTE: How many copies of each sound can be playing at once, any more than that cut off the oldest
+const soundVoices = 4
+
+// ebitenAudioBackend plays the generated sounds through ebiten's audio package.
+type ebitenAudioBackend struct {
+    context *audio.Context
+    voices [soundCount]*voicePool
+    music *audio.Player
+}
+
+func newEbitenAudioBackend() (*ebitenAudioBackend, error) {
+    context, err := audio.NewContext(audioSampleRate)
+    if err != nil {
+        return nil, err
+    }
+    result := &ebitenAudioBackend { context: context }
+    for sound := range result.voices {
+        pcm := synthesizeSound(SoundID(sound))
+        voices := make([]Voice, soundVoices)
+        for voice := range voices {
+            voices[voice], err = audio.NewPlayerFromBytes(context, pcm)
+            if err != nil {
+                return nil, err
+            }
+        }
+        result.voices[sound] = newVoicePool(voices)
+    }
+
+    music := synthesizeMusic()
+    loop := audio.NewInfiniteLoop(audio.BytesReadSeekCloser(music), int64(len(music)))
+    result.music, err = audio.NewPlayer(context, loop)
+    if err != nil {
+        return nil, err
+    }
+    if err := result.music.Play(); err != nil {
+        return nil, err
+    }
+    return result, nil
+}
+
+func (b *ebitenAudioBackend) PlaySound(sound SoundID, volume float64) {
+    // NOTE: A sound that fails to play is just a sound that doesn't get heard, so the error is dropped
+    b.voices[sound].Play(volume)
+}
+
+func (b *ebitenAudioBackend) SetMusicVolume(volume float64) {
+    b.music.SetVolume(volume)
+}
+
+func (b *ebitenAudioBackend) Update() error {
+    return b.context.Update()
+}
@@ -0,0 +1,90 @@
+// +build headless
+
+package main
+
+import (
+    "fmt"
+    "time"
+)
+
+// NOTE: The path normally grows by 60% every wave, which makes it far too long to actually play out
+//       to wave 30, so the benchmark just uses a long fixed path and spreads the enemies along it.
+const benchmarkPathLength = 2000
+
+// newBenchmarkState sets up a game with the given wave's worth of enemies spread out along the path
+// and towers spread out beside it.
+func newBenchmarkState(wave, towerCount int) *GameState {
+    g := newGameState(defaultGameParams())
+    for len(g.waypoints) < benchmarkPathLength {
+        g.addPathSegment()
+    }
+
+    // NOTE: Make the enemies tough enough that they all survive the whole benchmark
+    g.enemyHealth = 1 << 30
+    enemyCount := len(g.params.Waves.Wave(wave).SpawnQueue())
+    for i := 0; i < enemyCount; i++ {
+        waypoint := 1 + i*(len(g.waypoints)-2)/enemyCount
+        enemyType := i%len(enemyTypes)
+        g.enemies = append(g.enemies, g.newEnemy(enemyType, g.waypoints[waypoint-1], waypoint))
+    }
+
+    for i := 0; i < towerCount; i++ {
+        waypoint := 1 + i*(len(g.waypoints)-2)/towerCount
+        from := g.waypoints[waypoint-1]
+        to := g.waypoints[waypoint]
+        midpoint := from.Add(to).Mul(0.5)
+        side := Vec2 { -(to.y-from.y), to.x-from.x }.Normalized()
+        g.ghostTower.towerType = i%len(towerTypes)
+        g.addTower(midpoint.Add(side.Mul(0.5*g.params.PathSegmentLength)))
+    }
+    return g
+}
+
+// bruteForceTarget is how targeting worked before enemyGrid, kept here to compare its speed against.
+// NOTE: Whether the grid finds the same enemies is checked by the tests in spatial_test.go
+func bruteForceTarget(g *GameState, tower *Tower) {
+    tower.currentTarget = nil
+    for _,enemy := range g.enemies {
+        if enemy.position.Sub(tower.position).Magnitude() >= tower.Range() {
+            continue
+        }
+        if tower.isBetterTarget(enemy, tower.currentTarget, g.waypoints) {
+            tower.currentTarget = enemy
+        }
+    }
+}
+
+func reportBenchmark(name string, ticks int, elapsed time.Duration) {
+    perTick := elapsed/time.Duration(ticks)
+    fmt.Printf("%-24s %8d ticks %12v %10v/tick %10.0f ticks/sec\n",
+               name, ticks, elapsed, perTick, float64(ticks)/elapsed.Seconds())
+}
+
+func runBenchmark(wave, towerCount, ticks int) {
+    g := newBenchmarkState(wave, towerCount)
+    fmt.Printf("Wave %d: %d enemies, %d towers, %d path segments\n",
+               wave, len(g.enemies), len(g.towers), len(g.waypoints)-1)
+
+    start := time.Now()
+    for i := 0; i < ticks; i++ {
+        for _,tower := range g.towers {
+            bruteForceTarget(g, tower)
+        }
+    }
+    reportBenchmark("Targeting (brute force)", ticks, time.Since(start))
+
+    start = time.Now()
+    for i := 0; i < ticks; i++ {
+        g.enemyGrid.Rebuild(g.enemies, g.enemyGridCellSize())
+        for _,tower := range g.towers {
+            g.acquireTarget(tower)
+        }
+    }
+    reportBenchmark("Targeting (grid)", ticks, time.Since(start))
+
+    start = time.Now()
+    for i := 0; i < ticks; i++ {
+        g.Step(Input {})
+    }
+    reportBenchmark("Full simulation step", ticks, time.Since(start))
+}
@@ -9,6 +9,7 @@
 // _resources/enemy_3.png
 // _resources/enemy_4.png
 // _resources/enemy_4b.png
+// _resources/font.png
 // _resources/pathsegment.png
 // _resources/pathsegment_end.png
 // _resources/pathsegment_first.png
@@ -18,6 +19,7 @@
 // _resources/tower_3.png
 // _resources/tower_canbuild.png
 // _resources/tower_nocanbuild.png
+// _resources/waves.json
 // DO NOT EDIT!
 
 package main
@@ -214,6 +216,23 @@ func _resourcesEnemy_4bPng() (*asset, error) {
 	return a, nil
 }
 
+var __resourcesFontPng = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00p\x00\x00\x00N\b\x06\x00\x00\x00\xf8\x90\x92\x96\x00\x00\x06+IDATx\x9c\xec[\xed\x8e\xe38\f\xdb\t\xfa\xfe\xaf<\x87\xc3\x1d\x01\x0eA\u0252\xe3d\u04ad\x99\x1f\xb5I}Yv\xd2f\x16{\xfc\xd9xk\xec\r\xfc\x94\r\xfc\xfe\xfe\xfe\xc6\xd8\xf1\x91\u0785\xc6\xe9\xc6\x1d\xd9;}\xe4\xf3D\xa0\xe6\xe3\x8e$\xf8\x8ct\xe0\xdf\xf9\xd7\xd7\xd7Wd\u007f\x16\x88\x8f9pe\xce\xc7\u0701\xab/4\xec\xfb\u007fp#\xd1dm8\xdb\xde\x01\xce\x15\xe5\x8d\xf8+\xa1})\xa1[(\x9a\xcd`\x8d?\x1dT\xe3\xb9j\x15T|\x9c\x8d\xd6^\xf1\xb9\x1a\x9c\xf3\x85\xc1j\xf0\u0764'\x06\xdc\xf4i\xba\xb9I<G\xad\xac\xab\x96\x81\xfd\x80\x8a\x1f0\xdd/\x978\x03N.\x03\x9a\xc6TM\xe7@\x14\xa7\x82\x8a\x9f\xb3\x19\xe5\u0334\xd58\x95k\xc6\x19>\ua2e6\x00\xaaU\xc7\xccU\x90\xd9;\x8d9\xa7g\xfc\x15\xb83\u05cf\x84Qb\xe5y\x8e\xb1~\x02:\xaf \U000d9257\x01\x87s&\xae\U000d92736\xc0D\x0e7\a\x9c^\x81\xf3\x99\x893\x02\xd79\u39fe3\xb16&q\xb6\xd9{\xb3n\u072c\x8f}\x91\xdf\x1b\xb87\xf0\xc37\xf0\xbf\r|\xe9s7zIt/\x90\xfa\xac\x86\xae\xbc\xeaQ\u070a\x1f\xdbT\xfdT\a?\xd2V\xc1\xf5\xaek\x97\xd6\u0262\x1a\x82S>\xb2\u00f8\xa2i\\g\xc3\x18\xe9\xce\xd6\xf9T\xb4U\xe8\xc6s\xf6\x19wd\u007f\xe6\x82\xe1\x8f\u075e\xb8\\\x8cn\u070e}7\xf6\b\u070f\xae\u05adC\xfb_~\x84FWT\xc0\x99f\x8e\x16\xc6\v\xc8\xec\xaa\xf9\xaap\x8d\x03\xe7\u23b4\xc8^\xa1v\xd8D\xf0\xaa\xff\x19\x05\xadr#\x1d\x8fH\xd6\xd4N\xb5H\xe7O\x006\f\xc7\xc3\x1e\x884\u0321\x01\x8e\u02f4\u033e\x83(\xce\x0f\xde\x19U9\xf0\x91\xe6\xfc\xd5V\xe7\n\xe8.\xcfh\xde\xe1*Z\ag\xe3D\xfe\xca\x0f\x1f\xa1\xd9\xe5\x1e\x133\xc5U\xe3\xcc\xe4\xfa-\xe8c\u042d;ZW\xb5\x1f\u007f\\\xe0(\x89\xf2\x91]\xa4E\xf6\xcc;\x1bp\x99\x16\xe9\x15\xbf\xb3<\x00\xae\xea\xbb\xca\xfeK\x1d\xddi\xc0XO\x17\xe6\f\xf8\xab\xaeq\xa3\u04d6\xf9\x8d4\x9e3\xaa1\x9d\u007f\xa6Cs~\xd9\x1a\xcf\xd8Ucm\x10x\x133nc\xa3\x8dco\xe0\xde\xc0\xbd\x81\xbf\xb8\x81/\xf7<\xd7/I\xe8\u032b\xcfH\x87\x96\xf99\rz\xa69_\xe61v?\x04\\\\g\xaf\xb58\x9bHS\x1b\xc7e\x1a\u01b6F\x15\x94\x8b\xc6\xd9\\y\xe5T\xc7\\ygS\xd5\xc0e\u0688\x8bt\xb5\xcd4\x86\xber\xf0\x9cy\xa0\xa2\xa5\x8fP=\x158)\x98w\xc0q\xee\xc0]\xf9\xb4Gg0\xd3\xdb\x1f\x1b\xc8'bc\x8c\x99\xcd\xc3M\xe0|gn\x90\x97+\xa6\x13\x84m\xb5\xa0\x19d\xf1fs\xb1\xdf*\xb8\rX\x01lb5\xf6k\xb4\xd8\xd1\xe29\u044aEe\xfe\x95\xd8\\/\xec\xd5o\xb4\xa6+\x1a\xcd\x18\xf9 6\xe6\xa5GhT\u0417\x00\xfcSqg\x9d\x9dFwQ]\xc3\u172a\xce\x1d\\\xb5\u042brUc\\\xb9\x89\x15\xbc\\\x01\x95\r\x84\x0f\xfb\xc2/\x8b\t\xde\xf9)\xeft\a\xcd\xe7\xf2G`;\x8cq\x17G5*`\v\x1b\xf6\xab\xf8\x8f\xa0\xb5\x9c\x8d\xb7\xf1 \x1c\x86\xdb\x1b\xb87po\xe0]\x1b\xf8\n\xf8\x8d\x01f\xbf\x93V\u007f\x97\xbd4\xa0\x06\x8d\x12\xce\xfae\xbe\xee5\x06\xdc\x15\x9a\x8ey\xeej\xd4u\xf0\x98\xa11\x19\xa3>\x8c\xfc\x15/W\b\x02\xb8@\u031d\xf5svO\x81\xab+j\xf8J\xa0'\xd5\xde\x1c\xbf\u0650;.4C\x1b\xe4\xf4\xaci\x996\xcaY\x05\xe7\xa8\xc6x\x9b\x1f1\xba\xa0NC\xcf\xe2\xae\\\x9a\xa3\x92\xb3\xf4\x0f\xba\xd1\x15\xf9\xb9\xd3S\x8dy\x06\u0229\xb9\xb8\x1eW\at\xa7\xc1\xaf\x8b,\xe6J\xd8\xef\xc0\x15E*w\xc7b\"p\xee\x99:\xba\xf6\x95\xfe\xacB\xe9\x11\x9a\x9dB\x14\x89\xf9\x95@.\xd7\x14p+\xebqy\x9e\x86\xa3\xd38\xccGzf{7t\x13Fk\x89\xfcfQ\xcdw\u024b\xbc&\xcf\x16\x05\xdb\xe8.\xa84c\xd6O\xed\\\x9c\x19\xb8\x18\xd5z\xeeB\xbb\x98l\x13?\t\xba\xb9\u055e\xcc\xf8\xa9O\xc7w\xe3\xe18\x1a\xb6{\x03\x9f\xbc\x81\xd1m\xba\xf1F\xe8l\xe2\xca\r\xef\xe6\x05\xc0\xad\x8a]E%f\xa7\xc6%w\xe0;|)\xf2\xaf\xdcU\xbf4\x81J\xacN\xbe\xbbzy\x18\xeem\xb0\xb2I\x95X+\xf3\xdd\xf2\x1e\xe8N\x9d.\x82u\xd62\xbf\xc8g\xe4\x97Ac\xea\xeb\x8e\xea\xceW\xf9\xaa\x8d\xe6\xca\xc0q\xd8\x0fc\u868by\xd5Z@ \xf7\x8cg\r\xdcH\xe38\xaa1\a;\xc0\u0678y\xc5>\xe2\"\x1b\xb6\xe5\xb1\xc2i\xe0\xdc'\xc6\x00k\xe0Tk\u07c1\xb3\xa7 \xb2\xd5S\x17\xe5\x83]'\xe7(\xf7\x19\xa0\u0395\xb1gb\xa5\x1b\xe8\x1a\x165\xf87\x17\xa1\x87`&\xc6l\x9d\xb3\x87\xeaW~\xc4\xe8\xe6\xe9\xbc\xe3;\xe2\xab:l\xf0\xcb\xf4\xeef\xea\xe1\xb9\x1b\xafNq\x95\xb9\xe33\x8d\u7a81\xc38\u00ac\x9f\x1e\x10\x8c\xbbw\x17\xf2\xb3_\x14\x13\xd0y%\xber\x18\xbf=tq\x11w6\ue298\xab\xf1\xd7\xec\xe2U'\x94\xe3\xfeU\xa7~_\u03f8^\x05\x9b\x8d\x04\xdd;t\xdf\xd1\x0f\xba\xa3y3V\xe9\xcc9\xfd\xd4k\u012a\xabR\xd8'\x02\xbfd;\xfd\xf9\x95\r\xdcW~a\x13K\x8fX\xfd[\x9c\xee\xbe\xf2\xee\x13\x80\x8f\xf2\xaca\xae<4\x8c\x1d\xa7zES\xbe\xa39\xc0N\xed\x1df\xb5\x16\\a\x98;\x9e9\xa7;^9\xa7\xcf\xf8\xcdh\xd18\xe3*\xbd\xa8\xf8(*1F8:\xbf\x86Fv\xd0\xf1\b\xe0\xe2F\xbe\xab\xe0\x1a\xc2\xf5\xcc\xd4\xe1\xfc\xf0g;\x97\xaf\x13s\xa6\x1e\u0181` 2t\x8aEqh\x1c\xf83\xe0\x86\xb9\xa6\x9e\x85\xab3\xcbs\xa6\x06^\u02d2\xff\x1b\xc1\xc1P\x94\xe3y\x1e\x81\x17\x86\xcfl\xb1\x91V\xc9U\x01\xc7\xe7\xf1h}l\xfb\xf8\x17\xf9\xa8\u0419\x05h#4\x8e\xea\xd0\x1c\xcfs\xe68\x9e\xf2\xac\xf3\xd8\xf9\xb3\xc6@^\x97\x1f\xe3G@\v\\m\xffd\xb8\xb5t\u05c7\xaf\x88\xaa_\u05fe|\a~\u217bQ9\x8c+\xb8\xda~\x84\u007f\x06\x00 \xa1\x88d0\xac\x82\xf5\x00\x00\x00\x00IEND\xaeB`\x82")
+
+func _resourcesFontPngBytes() ([]byte, error) {
+	return __resourcesFontPng, nil
+}
+
+func _resourcesFontPng() (*asset, error) {
+	bytes, err := _resourcesFontPngBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "_resources/font.png", size: 1636, mode: os.FileMode(420), modTime: time.Unix(1792297802, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 var __resourcesPathsegmentPng = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00@\x00\x00\x00@\b\x02\x00\x00\x00%\v\xe6\x89\x00\x00\x00\x04gAMA\x00\x00\xb1\x8f\v\xfca\x05\x00\x00\x00\tpHYs\x00\x00\x0e\xc2\x00\x00\x0e\xc2\x01\x15(J\x80\x00\x00\x00\x19tEXtSoftware\x00paint.net 4.0.10\xad\n\n\xc0\x00\x00\x00yIDAThC\xed\xdaA\r\x80@\x10\xc0\xc0\xb5\x81't`\x03\x1d\xa89k|\xd6C\u04e4\xe3\xa0\xff\u0391\x9bOn^\xb9y\xe4\u659bK\xae\x00Z\x01\xb4\x02h\x05\xd0\n\xa0\x15@+\x80V\x00\xad\x00Z\x01\xb4\x02h\x05\xd0\n\xa0\x15@+\x80V\x00\xad\x00Z\x01\xb4\x02h\x05\xd0\n\xa0\x15@+\x80V\x00\xad\x00Z\x014\u007f\xc0\u078bZ\xfe\xf1u\x0f^-\xff\xfc\xbd\x17\xbb\xd49?h=\xc2jg\uac0a\x00\x00\x00\x00IEND\xaeB`\x82")
 
 func _resourcesPathsegmentPngBytes() ([]byte, error) {
@@ -367,6 +386,356 @@ func _resourcesTower_nocanbuildPng() (*asset, error) {
 	return a, nil
 }
 
+var __resourcesWavesJson = []byte(`{
+  "version": 1,
+  "waves": [
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 1, "interval": 10.0, "delay": 0.0 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0,
+      "healthIncrease": 1,
+      "bountyIncrease": 1
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 3, "interval": 3.333333, "delay": 0.0 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 4, "interval": 1.666667, "delay": 0.0 },
+        { "enemy": "Runner", "count": 1, "interval": 1.666667, "delay": 1.666667 },
+        { "enemy": "Armoured", "count": 1, "interval": 1.666667, "delay": 1.666667 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0,
+      "healthIncrease": 1
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 5, "interval": 1.0, "delay": 0.0 },
+        { "enemy": "Runner", "count": 2, "interval": 1.0, "delay": 1.0 },
+        { "enemy": "Armoured", "count": 2, "interval": 1.0, "delay": 1.0 },
+        { "enemy": "Splitter", "count": 1, "interval": 1.0, "delay": 1.0 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0
+    },
+    {
+      "label": "Boss wave!",
+      "groups": [
+        { "enemy": "Grunt", "count": 8, "interval": 0.666667, "delay": 0.0 },
+        { "enemy": "Runner", "count": 2, "interval": 0.666667, "delay": 0.666667 },
+        { "enemy": "Armoured", "count": 3, "interval": 0.666667, "delay": 0.666667 },
+        { "enemy": "Splitter", "count": 2, "interval": 0.666667, "delay": 0.666667 },
+        { "enemy": "Boss", "count": 1, "interval": 0.666667, "delay": 0.666667 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0,
+      "healthIncrease": 1,
+      "bountyIncrease": 1
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 11, "interval": 0.47619, "delay": 0.0 },
+        { "enemy": "Runner", "count": 3, "interval": 0.47619, "delay": 0.47619 },
+        { "enemy": "Armoured", "count": 4, "interval": 0.47619, "delay": 0.47619 },
+        { "enemy": "Splitter", "count": 3, "interval": 0.47619, "delay": 0.47619 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 15, "interval": 0.357143, "delay": 0.0 },
+        { "enemy": "Runner", "count": 4, "interval": 0.357143, "delay": 0.357143 },
+        { "enemy": "Armoured", "count": 5, "interval": 0.357143, "delay": 0.357143 },
+        { "enemy": "Splitter", "count": 4, "interval": 0.357143, "delay": 0.357143 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0,
+      "healthIncrease": 1
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 19, "interval": 0.277778, "delay": 0.0 },
+        { "enemy": "Runner", "count": 5, "interval": 0.277778, "delay": 0.277778 },
+        { "enemy": "Armoured", "count": 6, "interval": 0.277778, "delay": 0.277778 },
+        { "enemy": "Splitter", "count": 6, "interval": 0.277778, "delay": 0.277778 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 24, "interval": 0.222222, "delay": 0.0 },
+        { "enemy": "Runner", "count": 6, "interval": 0.222222, "delay": 0.222222 },
+        { "enemy": "Armoured", "count": 8, "interval": 0.222222, "delay": 0.222222 },
+        { "enemy": "Splitter", "count": 7, "interval": 0.222222, "delay": 0.222222 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0,
+      "healthIncrease": 1,
+      "bountyIncrease": 1
+    },
+    {
+      "label": "Boss wave!",
+      "groups": [
+        { "enemy": "Grunt", "count": 29, "interval": 0.181818, "delay": 0.0 },
+        { "enemy": "Runner", "count": 7, "interval": 0.181818, "delay": 0.181818 },
+        { "enemy": "Armoured", "count": 10, "interval": 0.181818, "delay": 0.181818 },
+        { "enemy": "Splitter", "count": 9, "interval": 0.181818, "delay": 0.181818 },
+        { "enemy": "Boss", "count": 1, "interval": 0.181818, "delay": 0.181818 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 35, "interval": 0.151515, "delay": 0.0 },
+        { "enemy": "Runner", "count": 9, "interval": 0.151515, "delay": 0.151515 },
+        { "enemy": "Armoured", "count": 11, "interval": 0.151515, "delay": 0.151515 },
+        { "enemy": "Splitter", "count": 11, "interval": 0.151515, "delay": 0.151515 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0,
+      "healthIncrease": 1
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 41, "interval": 0.128205, "delay": 0.0 },
+        { "enemy": "Runner", "count": 11, "interval": 0.128205, "delay": 0.128205 },
+        { "enemy": "Armoured", "count": 13, "interval": 0.128205, "delay": 0.128205 },
+        { "enemy": "Splitter", "count": 13, "interval": 0.128205, "delay": 0.128205 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 49, "interval": 0.10989, "delay": 0.0 },
+        { "enemy": "Runner", "count": 12, "interval": 0.10989, "delay": 0.10989 },
+        { "enemy": "Armoured", "count": 15, "interval": 0.10989, "delay": 0.10989 },
+        { "enemy": "Splitter", "count": 15, "interval": 0.10989, "delay": 0.10989 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0,
+      "healthIncrease": 1,
+      "bountyIncrease": 1
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 56, "interval": 0.095238, "delay": 0.0 },
+        { "enemy": "Runner", "count": 14, "interval": 0.095238, "delay": 0.095238 },
+        { "enemy": "Armoured", "count": 18, "interval": 0.095238, "delay": 0.095238 },
+        { "enemy": "Splitter", "count": 17, "interval": 0.095238, "delay": 0.095238 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0
+    },
+    {
+      "label": "Boss wave!",
+      "groups": [
+        { "enemy": "Grunt", "count": 64, "interval": 0.083333, "delay": 0.0 },
+        { "enemy": "Runner", "count": 16, "interval": 0.083333, "delay": 0.083333 },
+        { "enemy": "Armoured", "count": 20, "interval": 0.083333, "delay": 0.083333 },
+        { "enemy": "Splitter", "count": 20, "interval": 0.083333, "delay": 0.083333 },
+        { "enemy": "Boss", "count": 1, "interval": 0.083333, "delay": 0.083333 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0,
+      "healthIncrease": 1
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 72, "interval": 0.073529, "delay": 0.0 },
+        { "enemy": "Runner", "count": 19, "interval": 0.073529, "delay": 0.073529 },
+        { "enemy": "Armoured", "count": 23, "interval": 0.073529, "delay": 0.073529 },
+        { "enemy": "Splitter", "count": 22, "interval": 0.073529, "delay": 0.073529 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 82, "interval": 0.065359, "delay": 0.0 },
+        { "enemy": "Runner", "count": 21, "interval": 0.065359, "delay": 0.065359 },
+        { "enemy": "Armoured", "count": 25, "interval": 0.065359, "delay": 0.065359 },
+        { "enemy": "Splitter", "count": 25, "interval": 0.065359, "delay": 0.065359 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0,
+      "healthIncrease": 1,
+      "bountyIncrease": 1
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 92, "interval": 0.05848, "delay": 0.0 },
+        { "enemy": "Runner", "count": 22, "interval": 0.05848, "delay": 0.05848 },
+        { "enemy": "Armoured", "count": 29, "interval": 0.05848, "delay": 0.05848 },
+        { "enemy": "Splitter", "count": 28, "interval": 0.05848, "delay": 0.05848 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 101, "interval": 0.052632, "delay": 0.0 },
+        { "enemy": "Runner", "count": 26, "interval": 0.052632, "delay": 0.052632 },
+        { "enemy": "Armoured", "count": 32, "interval": 0.052632, "delay": 0.052632 },
+        { "enemy": "Splitter", "count": 31, "interval": 0.052632, "delay": 0.052632 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0,
+      "healthIncrease": 1
+    },
+    {
+      "label": "Boss wave!",
+      "groups": [
+        { "enemy": "Grunt", "count": 112, "interval": 0.047619, "delay": 0.0 },
+        { "enemy": "Runner", "count": 28, "interval": 0.047619, "delay": 0.047619 },
+        { "enemy": "Armoured", "count": 35, "interval": 0.047619, "delay": 0.047619 },
+        { "enemy": "Splitter", "count": 35, "interval": 0.047619, "delay": 0.047619 },
+        { "enemy": "Boss", "count": 1, "interval": 0.047619, "delay": 0.047619 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 124, "interval": 0.04329, "delay": 0.0 },
+        { "enemy": "Runner", "count": 30, "interval": 0.04329, "delay": 0.04329 },
+        { "enemy": "Armoured", "count": 39, "interval": 0.04329, "delay": 0.04329 },
+        { "enemy": "Splitter", "count": 38, "interval": 0.04329, "delay": 0.04329 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0,
+      "healthIncrease": 1,
+      "bountyIncrease": 1
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 135, "interval": 0.039526, "delay": 0.0 },
+        { "enemy": "Runner", "count": 34, "interval": 0.039526, "delay": 0.039526 },
+        { "enemy": "Armoured", "count": 42, "interval": 0.039526, "delay": 0.039526 },
+        { "enemy": "Splitter", "count": 42, "interval": 0.039526, "delay": 0.039526 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 147, "interval": 0.036232, "delay": 0.0 },
+        { "enemy": "Runner", "count": 37, "interval": 0.036232, "delay": 0.036232 },
+        { "enemy": "Armoured", "count": 46, "interval": 0.036232, "delay": 0.036232 },
+        { "enemy": "Splitter", "count": 46, "interval": 0.036232, "delay": 0.036232 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0,
+      "healthIncrease": 1
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 160, "interval": 0.033333, "delay": 0.0 },
+        { "enemy": "Runner", "count": 40, "interval": 0.033333, "delay": 0.033333 },
+        { "enemy": "Armoured", "count": 50, "interval": 0.033333, "delay": 0.033333 },
+        { "enemy": "Splitter", "count": 50, "interval": 0.033333, "delay": 0.033333 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0
+    },
+    {
+      "label": "Boss wave!",
+      "groups": [
+        { "enemy": "Grunt", "count": 173, "interval": 0.030769, "delay": 0.0 },
+        { "enemy": "Runner", "count": 43, "interval": 0.030769, "delay": 0.030769 },
+        { "enemy": "Armoured", "count": 55, "interval": 0.030769, "delay": 0.030769 },
+        { "enemy": "Splitter", "count": 54, "interval": 0.030769, "delay": 0.030769 },
+        { "enemy": "Boss", "count": 1, "interval": 0.030769, "delay": 0.030769 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0,
+      "healthIncrease": 1,
+      "bountyIncrease": 1
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 188, "interval": 0.02849, "delay": 0.0 },
+        { "enemy": "Runner", "count": 46, "interval": 0.02849, "delay": 0.02849 },
+        { "enemy": "Armoured", "count": 59, "interval": 0.02849, "delay": 0.02849 },
+        { "enemy": "Splitter", "count": 58, "interval": 0.02849, "delay": 0.02849 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 201, "interval": 0.026455, "delay": 0.0 },
+        { "enemy": "Runner", "count": 51, "interval": 0.026455, "delay": 0.026455 },
+        { "enemy": "Armoured", "count": 63, "interval": 0.026455, "delay": 0.026455 },
+        { "enemy": "Splitter", "count": 63, "interval": 0.026455, "delay": 0.026455 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0,
+      "healthIncrease": 1
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 217, "interval": 0.024631, "delay": 0.0 },
+        { "enemy": "Runner", "count": 54, "interval": 0.024631, "delay": 0.024631 },
+        { "enemy": "Armoured", "count": 68, "interval": 0.024631, "delay": 0.024631 },
+        { "enemy": "Splitter", "count": 67, "interval": 0.024631, "delay": 0.024631 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0
+    },
+    {
+      "groups": [
+        { "enemy": "Grunt", "count": 232, "interval": 0.022989, "delay": 0.0 },
+        { "enemy": "Runner", "count": 58, "interval": 0.022989, "delay": 0.022989 },
+        { "enemy": "Armoured", "count": 73, "interval": 0.022989, "delay": 0.022989 },
+        { "enemy": "Splitter", "count": 72, "interval": 0.022989, "delay": 0.022989 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0,
+      "healthIncrease": 1,
+      "bountyIncrease": 1
+    },
+    {
+      "label": "Boss wave!",
+      "groups": [
+        { "enemy": "Grunt", "count": 248, "interval": 0.021505, "delay": 0.0 },
+        { "enemy": "Runner", "count": 62, "interval": 0.021505, "delay": 0.021505 },
+        { "enemy": "Armoured", "count": 78, "interval": 0.021505, "delay": 0.021505 },
+        { "enemy": "Splitter", "count": 77, "interval": 0.021505, "delay": 0.021505 },
+        { "enemy": "Boss", "count": 1, "interval": 0.021505, "delay": 0.021505 }
+      ],
+      "speedMultiplier": 1.8,
+      "healthMultiplier": 1.0
+    }
+  ]
+}
+`)
+
+func _resourcesWavesJsonBytes() ([]byte, error) {
+	return __resourcesWavesJson, nil
+}
+
+func _resourcesWavesJson() (*asset, error) {
+	bytes, err := _resourcesWavesJsonBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "_resources/waves.json", size: 13738, mode: os.FileMode(420), modTime: time.Unix(1792297760, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -428,6 +797,7 @@ var _bindata = map[string]func() (*asset, error){
 	"_resources/enemy_3.png": _resourcesEnemy_3Png,
 	"_resources/enemy_4.png": _resourcesEnemy_4Png,
 	"_resources/enemy_4b.png": _resourcesEnemy_4bPng,
+	"_resources/font.png": _resourcesFontPng,
 	"_resources/pathsegment.png": _resourcesPathsegmentPng,
 	"_resources/pathsegment_end.png": _resourcesPathsegment_endPng,
 	"_resources/pathsegment_first.png": _resourcesPathsegment_firstPng,
@@ -437,6 +807,7 @@ var _bindata = map[string]func() (*asset, error){
 	"_resources/tower_3.png": _resourcesTower_3Png,
 	"_resources/tower_canbuild.png": _resourcesTower_canbuildPng,
 	"_resources/tower_nocanbuild.png": _resourcesTower_nocanbuildPng,
+	"_resources/waves.json": _resourcesWavesJson,
 }
 
 // AssetDir returns the file names below a certain
@@ -489,6 +860,7 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"enemy_3.png": &bintree{_resourcesEnemy_3Png, map[string]*bintree{}},
 		"enemy_4.png": &bintree{_resourcesEnemy_4Png, map[string]*bintree{}},
 		"enemy_4b.png": &bintree{_resourcesEnemy_4bPng, map[string]*bintree{}},
+		"font.png": &bintree{_resourcesFontPng, map[string]*bintree{}},
 		"pathsegment.png": &bintree{_resourcesPathsegmentPng, map[string]*bintree{}},
 		"pathsegment_end.png": &bintree{_resourcesPathsegment_endPng, map[string]*bintree{}},
 		"pathsegment_first.png": &bintree{_resourcesPathsegment_firstPng, map[string]*bintree{}},
@@ -498,6 +870,7 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"tower_3.png": &bintree{_resourcesTower_3Png, map[string]*bintree{}},
 		"tower_canbuild.png": &bintree{_resourcesTower_canbuildPng, map[string]*bintree{}},
 		"tower_nocanbuild.png": &bintree{_resourcesTower_nocanbuildPng, map[string]*bintree{}},
+		"waves.json": &bintree{_resourcesWavesJson, map[string]*bintree{}},
 	}},
 }}
 
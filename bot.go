@@ -0,0 +1,121 @@
+// +build headless
+
+package main
+
+// botStrategy is a configurable simulated player that, in between waves, keeps building towers
+// wherever they'll cover the most path that isn't already covered, until it runs out of credits
+// or places to build.
+type botStrategy struct {
+    spots func(g *GameState) []Vec2 // NOTE: The spots that the bot will consider building on
+    towerType int
+    // NOTE: Hoarding bots only spend their credits after a wave that some enemies got through, and
+    //       then on the most expensive towers they can afford
+    hoard bool
+
+    // NOTE: Building can take a few ticks, so we have to stick with the spot we picked until it's done
+    planned bool
+    plannedType int
+    plannedLoc Vec2
+}
+
+func newCornersBot() *botStrategy {
+    return &botStrategy { spots: pathCornerSpots, towerType: 0 }
+}
+
+func newCoverageBot() *botStrategy {
+    return &botStrategy { spots: pathSideSpots, towerType: 0 }
+}
+
+func newHoardingBot() *botStrategy {
+    return &botStrategy { spots: pathSideSpots, hoard: true }
+}
+
+// pathCornerSpots returns the spots on the inside of each turn in the path, which are right beside
+// both of the segments on either side of the turn.
+func pathCornerSpots(g *GameState) []Vec2 {
+    var result []Vec2
+    for i := 1; i+1 < len(g.waypoints); i++ {
+        inDir := g.waypoints[i].Sub(g.waypoints[i-1]).Normalized()
+        outDir := g.waypoints[i+1].Sub(g.waypoints[i]).Normalized()
+        if inDir.Dot(outDir) > 0.99 {
+            continue // NOTE: Not actually a corner
+        }
+        inside := outDir.Sub(inDir).Mul(0.5*g.params.PathSegmentLength)
+        result = append(result, g.waypoints[i].Add(inside))
+    }
+    return result
+}
+
+func (b *botStrategy) chooseTowerType(g *GameState) int {
+    if !b.hoard {
+        return b.towerType
+    }
+    result := 0
+    for towerType := range towerTypes {
+        cost := g.towerCosts[towerType]
+        if g.towerAllowed(towerType) && (cost <= g.credits) && (cost > g.towerCosts[result]) {
+            result = towerType
+        }
+    }
+    return result
+}
+
+// coverageScores returns how much each of the given spots would add to the towers' coverage of the
+// path, if a tower with the given range was built there. Waypoints that are already in range of
+// other towers count for less the more towers they're in range of.
+func coverageScores(g *GameState, spots []Vec2, attackRange float64) []float64 {
+    coverage := make([]int, len(g.waypoints))
+    for index,waypoint := range g.waypoints {
+        for _,tower := range g.towers {
+            if waypoint.Sub(tower.position).Magnitude() < tower.Range() {
+                coverage[index]++
+            }
+        }
+    }
+
+    result := make([]float64, len(spots))
+    for spotIndex,spot := range spots {
+        for index,waypoint := range g.waypoints {
+            if waypoint.Sub(spot).Magnitude() < attackRange {
+                result[spotIndex] += 1.0/float64(1+coverage[index])
+            }
+        }
+    }
+    return result
+}
+
+func (b *botStrategy) NextInput(g *GameState) Input {
+    if !g.canStartWave() {
+        return Input {}
+    }
+    if b.planned && canBuild(g, b.plannedType, b.plannedLoc) {
+        return buildInput(g, b.plannedType, b.plannedLoc)
+    }
+    b.planned = false
+    if b.hoard && (g.waveStats.leaked == 0) {
+        return Input { startWave: true }
+    }
+
+    towerType := b.chooseTowerType(g)
+    if g.credits < g.towerCosts[towerType] {
+        return Input { startWave: true }
+    }
+
+    spots := b.spots(g)
+    // NOTE: The range of the tower as it would be built, with TowerRangeScale and all
+    planned := Tower { towerType: towerType, scale: g.ghostTower.scale, rangeScale: g.ghostTower.rangeScale }
+    scores := coverageScores(g, spots, planned.Range())
+    bestScore := 0.0
+    for index,spot := range spots {
+        if (scores[index] > bestScore) && canBuild(g, towerType, spot) {
+            b.planned = true
+            b.plannedType = towerType
+            b.plannedLoc = spot
+            bestScore = scores[index]
+        }
+    }
+    if !b.planned {
+        return Input { startWave: true }
+    }
+    return buildInput(g, b.plannedType, b.plannedLoc)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "hash/fnv"
+    "io/ioutil"
+    "reflect"
+    "strings"
+    "time"
+)
+
+const (
+    dailyDateFormat = "2006-01-02"
+    dailyResultVersion = 1
+
+    dailyModifierChance = 0.3 // NOTE: The chance of each modifier being turned on for the day
+)
+
+// NOTE: The list path generator is left out, since it needs its directions written by hand
+var dailyPathGenerators = []string { "dragon", "hilbert", "gosper", "levy", "random" }
+
+func dailyDate(t time.Time) string {
+    return t.Format(dailyDateFormat)
+}
+
+// dailyParams works out the params for the daily challenge on the given date. Everything about the
+// challenge comes from hashing the date, so everyone gets the same one without having to ask a server.
+func dailyParams(date string) (GameParams, error) {
+    params := defaultGameParams()
+    if _,err := time.Parse(dailyDateFormat, date); err != nil {
+        return params, fmt.Errorf("invalid daily challenge date %q, expected YYYY-MM-DD", date)
+    }
+    hash := fnv.New64a()
+    hash.Write([]byte("idoad-daily-" + date))
+    rng := newRNG(int64(hash.Sum64()))
+
+    params.Daily = date
+    params.Seed = rng.PickSeed()
+    params.PathGenerator = dailyPathGenerators[rng.Intn(len(dailyPathGenerators))]
+
+    // NOTE: The basic tower is always allowed, along with at least two of the others
+    others := make([]int, 0, len(towerTypes)-1)
+    for towerType := 1; towerType < len(towerTypes); towerType++ {
+        others = append(others, towerType)
+    }
+    for i := len(others)-1; i > 0; i-- {
+        j := rng.Intn(i+1)
+        others[i], others[j] = others[j], others[i]
+    }
+    allowed := make([]bool, len(towerTypes))
+    allowed[0] = true
+    for _,towerType := range others[:2 + rng.Intn(len(others)-2)] {
+        allowed[towerType] = true
+    }
+    for towerType := range towerTypes {
+        if allowed[towerType] {
+            params.AllowedTowers = append(params.AllowedTowers, strings.ToLower(towerTypes[towerType].name))
+        }
+    }
+
+    for _,name := range modifierNames {
+        if rng.Chance(dailyModifierChance) {
+            params.Modifiers = append(params.Modifiers, name)
+        }
+    }
+    return params, params.validate()
+}
+
+// DailyResult is what a daily challenge game exports once it's lost: the score that it claims,
+// along with the replay that it can be checked against.
+type DailyResult struct {
+    Version int `json:"version"`
+    Date string `json:"date"`
+    Score HighScore `json:"score"`
+    Replay *Replay `json:"replay"`
+}
+
+func newDailyResult(g *GameState, replay *Replay) *DailyResult {
+    return &DailyResult {
+        Version: dailyResultVersion,
+        Date: g.params.Daily,
+        Score: g.HighScore(time.Now()),
+        Replay: replay,
+    }
+}
+
+// DailyRecorder records the current attempt at the daily challenge, starting over whenever the game
+// is restarted, so that each result only has the replay of the game that it's for.
+type DailyRecorder struct {
+    recorder *ReplayRecorder
+}
+
+func newDailyRecorder(params GameParams) *DailyRecorder {
+    return &DailyRecorder { recorder: newReplayRecorder(params, nil) }
+}
+
+// Record should be called with the input for every tick, before it's passed to GameState.Step.
+func (d *DailyRecorder) Record(g *GameState, input Input) {
+    if input.restart && (g.lives == 0) {
+        d.recorder = newReplayRecorder(g.params, nil)
+    }
+    d.recorder.Record(input)
+}
+
+func (d *DailyRecorder) Result(g *GameState) *DailyResult {
+    return newDailyResult(g, d.recorder.Replay())
+}
+
+// WriteFileIfBetter writes the result out unless there's already one for the same day in the file
+// that's at least as good, so that playing the challenge again never loses your best game. It
+// returns whether it wrote the file.
+// NOTE: A file that can't be read is overwritten, since the new result is better than nothing
+func (r *DailyResult) WriteFileIfBetter(path string) (bool, error) {
+    if existing, err := loadDailyResult(path); err == nil {
+        if (existing.Date == r.Date) && !r.Score.betterThan(&existing.Score) {
+            return false, nil
+        }
+    }
+    return true, r.WriteFile(path)
+}
+
+func (r *DailyResult) WriteFile(path string) error {
+    data, err := json.Marshal(r)
+    if err != nil {
+        return err
+    }
+    return ioutil.WriteFile(path, data, 0644)
+}
+
+func loadDailyResult(path string) (*DailyResult, error) {
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    result := &DailyResult {}
+    if err := json.Unmarshal(data, result); err != nil {
+        return nil, fmt.Errorf("failed to parse daily result %s: %v", path, err)
+    }
+    if result.Version != dailyResultVersion {
+        return nil, fmt.Errorf("daily result %s has version %d, but only version %d is supported",
+                               path, result.Version, dailyResultVersion)
+    }
+    if result.Replay == nil {
+        return nil, fmt.Errorf("daily result %s doesn't have a replay", path)
+    }
+    return result, nil
+}
+
+// Verify plays the result's replay back, and checks that it's the daily challenge it says it is and
+// that it really does end with the score it claims.
+func (r *DailyResult) Verify() error {
+    params, err := dailyParams(r.Date)
+    if err != nil {
+        return err
+    }
+    if r.Replay.Version != replayVersion {
+        return fmt.Errorf("the replay has version %d, but only version %d is supported", r.Replay.Version, replayVersion)
+    }
+    if r.Replay.Start != nil {
+        return fmt.Errorf("the replay starts from a saved game rather than the start of the challenge")
+    }
+    // NOTE: Comparing them as JSON is the simplest way to compare everything, waves and all
+    expected, err := json.Marshal(params)
+    if err != nil {
+        return err
+    }
+    actual, err := json.Marshal(r.Replay.Params)
+    if err != nil {
+        return err
+    }
+    if !bytes.Equal(expected, actual) {
+        return fmt.Errorf("the replay wasn't played with the params for the daily challenge on %s", r.Date)
+    }
+
+    g := newGameStateFromReplay(r.Replay)
+    player := newReplayPlayer(r.Replay)
+    for !player.Finished() {
+        g.Step(player.Next())
+    }
+    if g.lives > 0 {
+        return fmt.Errorf("the game in the replay was never lost")
+    }
+    score := g.HighScore(time.Now())
+    score.Date = r.Score.Date
+    if !reflect.DeepEqual(score, r.Score) {
+        return fmt.Errorf("the result claims %+v, but playing back the replay gives %+v", r.Score, score)
+    }
+    return nil
+}
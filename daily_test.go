@@ -0,0 +1,83 @@
+// +build headless
+
+package main
+
+import (
+    "path/filepath"
+    "reflect"
+    "testing"
+)
+
+// playDailyAttempt plays the daily challenge with the strategy until it's lost, recording it the
+// same way that the game does.
+func playDailyAttempt(t *testing.T, g *GameState, strategy Strategy, recorder *DailyRecorder) {
+    t.Helper()
+    for ticks := 0; g.lives > 0; ticks++ {
+        if ticks >= 60*60*60 {
+            t.Fatalf("the strategy still hadn't lost the daily challenge after an hour")
+        }
+        input := strategy.NextInput(g)
+        recorder.Record(g, input)
+        g.Step(input)
+    }
+}
+
+func TestDailyResultVerifies(t *testing.T) {
+    params, err := dailyParams("2026-03-14")
+    if err != nil {
+        t.Fatal(err)
+    }
+    g := newGameState(params)
+    recorder := newDailyRecorder(params)
+    path := filepath.Join(t.TempDir(), "daily.json")
+
+    none, err := newStrategy("none", 0)
+    if err != nil {
+        t.Fatal(err)
+    }
+    playDailyAttempt(t, g, none, recorder)
+    first := recorder.Result(g)
+    if written, err := first.WriteFileIfBetter(path); !written || (err != nil) {
+        t.Fatalf("the first result wasn't written (%v)", err)
+    }
+
+    // NOTE: The second attempt starts from the restart, so its replay shouldn't have the first one in it
+    builder, err := newStrategy("path", 0)
+    if err != nil {
+        t.Fatal(err)
+    }
+    recorder.Record(g, Input { restart: true })
+    g.Step(Input { restart: true })
+    playDailyAttempt(t, g, builder, recorder)
+    second := recorder.Result(g)
+    if !second.Score.betterThan(&first.Score) {
+        t.Fatalf("building towers (%+v) didn't do any better than not building any (%+v)", second.Score, first.Score)
+    }
+    if written, err := second.WriteFileIfBetter(path); !written || (err != nil) {
+        t.Fatalf("the better result wasn't written (%v)", err)
+    }
+    if written, err := first.WriteFileIfBetter(path); written || (err != nil) {
+        t.Fatalf("the worse result replaced the better one (%v)", err)
+    }
+
+    loaded, err := loadDailyResult(path)
+    if err != nil {
+        t.Fatalf("failed to load the result: %v", err)
+    }
+    if err := loaded.Verify(); err != nil {
+        t.Fatalf("the result from the second attempt doesn't verify: %v", err)
+    }
+    if !reflect.DeepEqual(loaded.Score, second.Score) {
+        t.Errorf("expected the file to have the second attempt's score %+v, but got %+v", second.Score, loaded.Score)
+    }
+
+    loaded.Score.Killed++
+    if err := loaded.Verify(); err == nil {
+        t.Errorf("a result claiming an extra kill still verified")
+    }
+    loaded.Score.Killed--
+    loaded.Date = "2026-03-15"
+    if err := loaded.Verify(); err == nil {
+        t.Errorf("a result claiming to be for a different day still verified")
+    }
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+    "fmt"
+    "math"
+    "strings"
+)
+
+// DifficultyPreset scales the parts of the game that decide how hard it is. The starting values
+// scale the ones from the params, and the growth values scale how much each wave's multipliers
+// (and each tower type's cost growth) exceed 1, so 1.0 leaves everything as it is.
+type DifficultyPreset struct {
+    name string
+    livesScale float64
+    creditsScale float64
+    enemyHealthGrowth float64
+    enemySpeedGrowth float64
+    towerCostGrowth float64
+    pathGrowth float64 // NOTE: How many times longer the path gets after every wave
+}
+
+var difficultyPresets = []DifficultyPreset {
+    {
+        name: "easy",
+        livesScale: 2.0,
+        creditsScale: 2.0,
+        enemyHealthGrowth: 0.75,
+        enemySpeedGrowth: 0.75,
+        towerCostGrowth: 0.8,
+        pathGrowth: 1.7,
+    },
+    {
+        name: "normal",
+        livesScale: 1.0,
+        creditsScale: 1.0,
+        enemyHealthGrowth: 1.0,
+        enemySpeedGrowth: 1.0,
+        towerCostGrowth: 1.0,
+        pathGrowth: 1.6,
+    },
+    {
+        name: "hard",
+        livesScale: 0.6,
+        creditsScale: 1.0,
+        enemyHealthGrowth: 1.25,
+        enemySpeedGrowth: 1.2,
+        towerCostGrowth: 1.2,
+        pathGrowth: 1.5,
+    },
+    {
+        name: "nightmare",
+        livesScale: 0.3,
+        creditsScale: 0.5,
+        enemyHealthGrowth: 1.5,
+        enemySpeedGrowth: 1.4,
+        towerCostGrowth: 1.4,
+        pathGrowth: 1.4,
+    },
+}
+
+func difficultyByName(name string) (*DifficultyPreset, bool) {
+    for index := range difficultyPresets {
+        if difficultyPresets[index].name == name {
+            return &difficultyPresets[index], true
+        }
+    }
+    return nil, false
+}
+
+func difficultyNames() []string {
+    var result []string
+    for _,preset := range difficultyPresets {
+        result = append(result, preset.name)
+    }
+    return result
+}
+
+// scaleStart scales one of the starting values, but never below the given minimum.
+func scaleStart(value int, scale float64, minimum int) int {
+    return int(math.Max(float64(minimum), math.Floor(scale*float64(value) + 0.5)))
+}
+
+// scaleGrowth scales how much a multiplier grows something by, rather than the multiplier itself,
+// so that e.g. doubling a growth of 1.2 gives 1.4 rather than 2.4.
+func scaleGrowth(multiplier, scale float64) float64 {
+    return 1.0 + (multiplier-1.0)*scale
+}
+
+// Modifier is an optional rule that changes how the game plays, on top of the difficulty.
+type Modifier int
+
+const (
+    modifierNoSelling Modifier = iota // NOTE: Towers can't be sold
+    modifierRegeneration // NOTE: Enemies heal over time
+    modifierDoublePathGrowth // NOTE: The path grows twice after every wave
+
+    modifierCount
+)
+
+var modifierNames = [modifierCount]string {
+    modifierNoSelling: "no-selling",
+    modifierRegeneration: "regeneration",
+    modifierDoublePathGrowth: "double-path-growth",
+}
+
+var modifierLabels = [modifierCount]string {
+    modifierNoSelling: "No selling",
+    modifierRegeneration: "Regenerating enemies",
+    modifierDoublePathGrowth: "Double path growth",
+}
+
+// NOTE: The fraction of its starting health that an enemy heals every second with modifierRegeneration
+const enemyRegenerationRate = 0.1
+
+// regenerate heals the enemy for modifierRegeneration, up to its starting health.
+func (e *Enemy) regenerate() {
+    if (e.health <= 0) || (e.health >= e.maxHealth) {
+        e.regenerationOwed = 0.0
+        return
+    }
+    e.regenerationOwed += enemyRegenerationRate*float64(e.maxHealth)*deltaTime
+    wholeHealth := math.Floor(e.regenerationOwed)
+    e.health = minInt(e.maxHealth, e.health + int(wholeHealth))
+    e.regenerationOwed -= wholeHealth
+}
+
+func modifierByName(name string) (Modifier, bool) {
+    for index,modifierName := range modifierNames {
+        if modifierName == name {
+            return Modifier(index), true
+        }
+    }
+    return 0, false
+}
+
+func (p *GameParams) validateDifficulty() error {
+    if _,ok := difficultyByName(p.Difficulty); !ok {
+        return fmt.Errorf("unknown difficulty %q, expected one of: %s", p.Difficulty, strings.Join(difficultyNames(), ", "))
+    }
+    for _,name := range p.Modifiers {
+        if _,ok := modifierByName(name); !ok {
+            return fmt.Errorf("unknown modifier %q, expected some of: %s", name, strings.Join(modifierNames[:], ", "))
+        }
+    }
+    return nil
+}
+
+// DifficultyPreset returns the params' difficulty preset, which must already have been validated.
+func (p *GameParams) DifficultyPreset() *DifficultyPreset {
+    preset, _ := difficultyByName(p.Difficulty)
+    return preset
+}
+
+// difficultyTitle capitalizes a difficulty's name, for showing to the player.
+func difficultyTitle(name string) string {
+    if name == "" {
+        return name
+    }
+    return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// DifficultyLabel describes the difficulty and modifiers, for showing alongside the player's score.
+func (p *GameParams) DifficultyLabel() string {
+    result := difficultyTitle(p.Difficulty)
+    for _,name := range p.Modifiers {
+        modifier, _ := modifierByName(name)
+        result += ", " + modifierLabels[modifier]
+    }
+    return result
+}
+
+func (g *GameState) hasModifier(modifier Modifier) bool {
+    return g.modifiers[modifier]
+}
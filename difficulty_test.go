@@ -0,0 +1,144 @@
+package main
+
+import "testing"
+
+// enemyHealthAtWave starts waves one after another on the given difficulty, without playing them,
+// and returns the health that a basic enemy spawns with on the last one.
+func enemyHealthAtWave(difficulty string, wave int) int {
+    params := defaultGameParams()
+    params.Difficulty = difficulty
+    g := newGameState(params)
+    for g.currentWave < wave {
+        g.startRound()
+    }
+    return g.newEnemy(0, Vec2 {}, 0).health
+}
+
+// NOTE: The shipped waves only add a little health at a time, which used to be rounded away entirely
+func TestDifficultyScalesEnemyHealth(t *testing.T) {
+    easy := enemyHealthAtWave("easy", 10)
+    normal := enemyHealthAtWave("normal", 10)
+    hard := enemyHealthAtWave("hard", 10)
+    if !(easy < normal) || !(normal < hard) {
+        t.Errorf("by wave 10, enemies should get tougher with the difficulty, but they have %d, %d and %d health on easy, normal and hard",
+                 easy, normal, hard)
+    }
+}
+
+// difficultyAfterWaves starts a game on the given difficulty, and starts (without playing) waves
+// until it gets to the given one.
+func difficultyAfterWaves(t *testing.T, difficulty string, wave int) *GameState {
+    t.Helper()
+    params := defaultGameParams()
+    params.Difficulty = difficulty
+    if err := params.validate(); err != nil {
+        t.Fatal(err)
+    }
+    g := newGameState(params)
+    for g.currentWave < wave {
+        g.startRound()
+    }
+    return g
+}
+
+// NOTE: Each preset is compared against normal, in the direction its numbers say it should go
+func TestDifficultyPresets(t *testing.T) {
+    normalStart := difficultyAfterWaves(t, "normal", 0)
+    normalLater := difficultyAfterWaves(t, "normal", 10)
+    normalLater.waypoints = make([]Vec2, 100)
+    normalLater.endRound()
+    for _,preset := range difficultyPresets {
+        start := difficultyAfterWaves(t, preset.name, 0)
+        later := difficultyAfterWaves(t, preset.name, 10)
+        // NOTE: The path hasn't grown since none of the waves were played, so it's made long enough to round to different lengths
+        later.waypoints = make([]Vec2, 100)
+        later.endRound()
+        checks := []struct {
+            name string
+            scale float64
+            value float64
+            normal float64
+        } {
+            { "starting lives", preset.livesScale, float64(start.lives), float64(normalStart.lives) },
+            { "starting credits", preset.creditsScale, float64(start.credits), float64(normalStart.credits) },
+            { "enemy health by wave 10", preset.enemyHealthGrowth, later.enemyHealth, normalLater.enemyHealth },
+            { "enemy speed by wave 10", preset.enemySpeedGrowth, later.enemySpeed, normalLater.enemySpeed },
+            { "tower cost by wave 10", preset.towerCostGrowth, float64(later.towerCosts[0]), float64(normalLater.towerCosts[0]) },
+            { "path growth after wave 10", preset.pathGrowth/1.6, float64(later.targetWaypointCount), float64(normalLater.targetWaypointCount) },
+        }
+        for _,check := range checks {
+            switch {
+            case (check.scale > 1.0) && !(check.value > check.normal):
+                t.Errorf("%s should have more %s than normal, but has %g vs %g", preset.name, check.name, check.value, check.normal)
+            case (check.scale < 1.0) && !(check.value < check.normal):
+                t.Errorf("%s should have less %s than normal, but has %g vs %g", preset.name, check.name, check.value, check.normal)
+            case (check.scale == 1.0) && (check.value != check.normal):
+                t.Errorf("%s should have the same %s as normal, but has %g vs %g", preset.name, check.name, check.value, check.normal)
+            }
+        }
+        // NOTE: Nothing scales the bounties, so the difficulty shouldn't change them either
+        if later.enemyBounty != normalLater.enemyBounty {
+            t.Errorf("%s has a bounty of %d by wave 10, but normal has %d", preset.name, later.enemyBounty, normalLater.enemyBounty)
+        }
+    }
+}
+
+func TestNoSellingModifier(t *testing.T) {
+    for _,noSelling := range []bool { false, true } {
+        params := defaultGameParams()
+        if noSelling {
+            params.Modifiers = []string { modifierNames[modifierNoSelling] }
+        }
+        g := newGameState(params)
+        site := findBuildSite(t, g, 3)
+        build(t, g, site)
+        g.Step(Input { click: true, cursorLoc: site })
+        credits := g.credits
+        g.Step(Input { sell: true })
+        if sold := (len(g.towers) == 0); sold == noSelling {
+            t.Errorf("with no-selling %v, selling the tower left %d towers", noSelling, len(g.towers))
+        }
+        if sold := (g.credits > credits); sold == noSelling {
+            t.Errorf("with no-selling %v, selling the tower took the credits from %d to %d", noSelling, credits, g.credits)
+        }
+    }
+}
+
+func TestRegenerationModifier(t *testing.T) {
+    for _,regeneration := range []bool { false, true } {
+        params := defaultGameParams()
+        if regeneration {
+            params.Modifiers = []string { modifierNames[modifierRegeneration] }
+        }
+        g := newGameState(params)
+        g.enemyHealth = 100.0
+        enemy := g.newEnemy(0, g.waypoints[0], 0)
+        enemy.health = 50
+        g.enemies = append(g.enemies, enemy)
+        for tick := 0; tick < 60; tick++ {
+            enemy.Update(g)
+        }
+        // NOTE: A second of regeneration heals a tenth of the enemy's health, give or take rounding
+        if regeneration && ((enemy.health < 59) || (enemy.health > 60)) {
+            t.Errorf("with regeneration, an enemy on 50 of 100 health had %d health a second later instead of 60", enemy.health)
+        }
+        if !regeneration && (enemy.health != 50) {
+            t.Errorf("without regeneration, an enemy on 50 of 100 health had %d health a second later", enemy.health)
+        }
+    }
+}
+
+func TestDoublePathGrowthModifier(t *testing.T) {
+    params := defaultGameParams()
+    params.Modifiers = []string { modifierNames[modifierDoublePathGrowth] }
+    doubled := newGameState(params)
+    normal := newGameState(defaultGameParams())
+    for _,g := range []*GameState { doubled, normal } {
+        g.startRound()
+        g.endRound()
+    }
+    if expected := int(float64(normal.targetWaypointCount)*normal.difficulty.pathGrowth); doubled.targetWaypointCount != expected {
+        t.Errorf("with double path growth, the path should grow to %d waypoints, but it's growing to %d (normally %d)",
+                 expected, doubled.targetWaypointCount, normal.targetWaypointCount)
+    }
+}
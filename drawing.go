@@ -1,3 +1,5 @@
+// +build !headless
+
 package main
 
 import (
@@ -7,6 +9,7 @@ import (
 )
 
 func transformForCamera(opts *ebiten.DrawImageOptions) {
+    camera := &viewCamera.view
     opts.GeoM.Translate(-camera.MinX(), -camera.MinY())
     opts.GeoM.Scale(screenWidth/camera.size.x, screenHeight/camera.size.y)
 }
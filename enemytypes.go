@@ -0,0 +1,90 @@
+package main
+
+// EnemyType describes one kind of enemy. Health, speed and bounty are all relative to the values
+// for the current wave, so that every kind of enemy keeps getting harder as the waves go on.
+type EnemyType struct {
+    name string
+
+    healthScale float64
+    speedScale float64
+    bountyScale float64
+    armour int // NOTE: Subtracted from the damage of every hit, but every hit always does at least 1
+    size float64
+
+    frames []int // NOTE: Indices into enemyImg, these are cycled through for the walk animation
+    tint [3]float64
+
+    // NOTE: When an enemy with splitCount > 0 dies, it spawns that many enemies of splitInto
+    splitInto int
+    splitCount int
+}
+
+const (
+    enemyGrunt = iota
+    enemyRunner
+    enemyArmoured
+    enemySplitter
+    enemySpawnling
+    enemyBoss
+)
+
+var enemyTypes = []EnemyType {
+    enemyGrunt: {
+        name: "Grunt",
+        healthScale: 1.0,
+        speedScale: 1.0,
+        bountyScale: 1.0,
+        size: 1.0,
+        frames: []int { 0, 1, 2, 3, 4, 5 },
+        tint: [3]float64 { 1.0, 1.0, 1.0 },
+    },
+    enemyRunner: {
+        name: "Runner",
+        healthScale: 0.5,
+        speedScale: 1.6,
+        bountyScale: 1.0,
+        size: 0.8,
+        frames: []int { 0, 1, 4, 5 },
+        tint: [3]float64 { 1.0, 0.9, 0.5 },
+    },
+    enemyArmoured: {
+        name: "Armoured",
+        healthScale: 1.5,
+        speedScale: 0.7,
+        bountyScale: 2.0,
+        armour: 1,
+        size: 1.2,
+        frames: []int { 2, 3 },
+        tint: [3]float64 { 0.6, 0.65, 0.8 },
+    },
+    enemySplitter: {
+        name: "Splitter",
+        healthScale: 1.0,
+        speedScale: 0.9,
+        bountyScale: 1.0,
+        size: 1.1,
+        frames: []int { 0, 1, 2, 3, 4, 5 },
+        tint: [3]float64 { 0.7, 1.0, 0.6 },
+        splitInto: enemySpawnling,
+        splitCount: 2,
+    },
+    enemySpawnling: {
+        name: "Spawnling",
+        healthScale: 0.5,
+        speedScale: 1.3,
+        bountyScale: 0.5,
+        size: 0.6,
+        frames: []int { 0, 1, 2, 3, 4, 5 },
+        tint: [3]float64 { 0.7, 1.0, 0.6 },
+    },
+    enemyBoss: {
+        name: "Boss",
+        healthScale: 10.0,
+        speedScale: 0.5,
+        bountyScale: 10.0,
+        armour: 2,
+        size: 2.0,
+        frames: []int { 0, 1, 2, 3, 4, 5 },
+        tint: [3]float64 { 1.0, 0.5, 0.5 },
+    },
+}
@@ -1,59 +1,211 @@
 package main
 
-import "math"
+import (
+    "math"
+    "sort"
+)
 
 type Enemy struct {
+    enemyType int
     health int
+    maxHealth int
+    regenerationOwed float64 // NOTE: Healing builds up here until there's at least a whole point of it
+    speed float64
+    armour int
+    bounty int
     position Vec2
+    prevPosition Vec2 // NOTE: Where the enemy was on the previous tick, so rendering can interpolate
     currentWaypoint int
+    size float64 // NOTE: The enemy's diameter
+
+    effects []StatusEffect
 
     animFrame int
     animFrameDuration float64
 }
 
-func (e *Enemy) Update() {
+func (e *Enemy) Type() *EnemyType {
+    return &enemyTypes[e.enemyType]
+}
+
+func (e *Enemy) Radius() float64 {
+    return 0.5*e.size
+}
+
+// CurrentSpeed returns how fast the enemy is moving along the path, including any status effects.
+func (e *Enemy) CurrentSpeed() float64 {
+    return e.speed*e.speedMultiplier()
+}
+
+// PredictPosition returns where the enemy will be after the given time, if it carries on along the
+// path at its current speed.
+func (e *Enemy) PredictPosition(time float64, waypoints []Vec2) Vec2 {
+    result := e.position
+    distance := e.CurrentSpeed()*time
+    for waypoint := e.currentWaypoint; (distance > 0.0) && (waypoint < len(waypoints)); waypoint++ {
+        offset := waypoints[waypoint].Sub(result)
+        offsetDist := offset.Magnitude()
+        if offsetDist > distance {
+            return result.Add(offset.Normalized().Mul(distance))
+        }
+        result = waypoints[waypoint]
+        distance -= offsetDist
+    }
+    return result
+}
+
+func (e *Enemy) Update(g *GameState) {
+    e.prevPosition = e.position
+    speed := e.CurrentSpeed()
+    e.updateEffects()
+    if g.hasModifier(modifierRegeneration) {
+        e.regenerate()
+    }
+
     simTime := deltaTime
-    for (simTime > 0) && (e.currentWaypoint < len(waypoints)) {
-        moveDist := enemySpeed * simTime
-        offset := waypoints[e.currentWaypoint].Sub(e.position)
+    for (simTime > 0) && (speed > 0.0) && (e.currentWaypoint < len(g.waypoints)) {
+        moveDist := speed * simTime
+        offset := g.waypoints[e.currentWaypoint].Sub(e.position)
         offsetDist := offset.Magnitude()
         if offsetDist > moveDist {
             e.position = e.position.Add(offset.Normalized().Mul(moveDist))
             simTime = 0.0
         } else {
-            timeToWaypoint := offsetDist/enemySpeed
-            e.position = waypoints[e.currentWaypoint]
+            timeToWaypoint := offsetDist/speed
+            e.position = g.waypoints[e.currentWaypoint]
             e.currentWaypoint++
             simTime -= timeToWaypoint
         }
     }
 
+    frameCount := len(e.Type().frames)
     e.animFrameDuration -= deltaTime
     if e.animFrameDuration < 0 {
-        e.animFrameDuration += 0.40/float64(6)
-        e.animFrame = (e.animFrame+1)%6
+        e.animFrameDuration += 0.40/float64(frameCount)
+        e.animFrame = (e.animFrame+1)%frameCount
+    }
+}
+
+// TakeDamage reduces the enemy's health by the given damage, less its armour.
+func (e *Enemy) TakeDamage(damage int) {
+    damage -= e.Armour()
+    if damage < 1 {
+        damage = 1
     }
+    e.health -= damage
+}
+
+// IsAheadOf reports whether this enemy is further along the path than the other one.
+func (e *Enemy) IsAheadOf(other *Enemy, waypoints []Vec2) bool {
+    if e.currentWaypoint != other.currentWaypoint {
+        return e.currentWaypoint > other.currentWaypoint
+    }
+    if e.currentWaypoint >= len(waypoints) {
+        return false
+    }
+    nextWaypoint := waypoints[e.currentWaypoint]
+    return nextWaypoint.Sub(e.position).Magnitude() < nextWaypoint.Sub(other.position).Magnitude()
+}
+
+type TargetMode int
+
+const (
+    targetFirst TargetMode = iota // NOTE: Furthest along the path
+    targetLast
+    targetStrongest
+    targetClosest
+
+    targetModeCount
+)
+
+var targetModeNames = [targetModeCount]string { "First", "Last", "Strongest", "Closest" }
+
+func (m TargetMode) String() string {
+    return targetModeNames[m]
 }
 
 type Tower struct {
     position Vec2
     scale float64
     cost int
+    towerType int
+    level int
+    spent int
+    targetMode TargetMode
 
     animFrame int
     animFrameDuration float64
 
     timeTillAttack float64
-    attackRange float64
+    rangeScale float64 // NOTE: The game's TowerRangeScale, which applies on top of the type's range
     currentTarget *Enemy
 }
 
-func (t *Tower) Update() {
+func (t *Tower) Type() *TowerType {
+    return &towerTypes[t.towerType]
+}
+
+func (t *Tower) Range() float64 {
+    result := t.Type().attackRange*t.rangeScale*t.scale
+    for _,upgrade := range towerUpgrades[:t.level] {
+        result *= upgrade.rangeScale
+    }
+    return result
+}
+
+func (t *Tower) Damage() int {
+    result := t.Type().damage
+    for _,upgrade := range towerUpgrades[:t.level] {
+        result += upgrade.damageBonus
+    }
+    return result
+}
+
+func (t *Tower) Cooldown() float64 {
+    result := t.Type().cooldown
+    for _,upgrade := range towerUpgrades[:t.level] {
+        result *= upgrade.cooldownScale
+    }
+    return result
+}
+
+func (t *Tower) CanUpgrade() bool {
+    return t.level < len(towerUpgrades)
+}
+
+func (t *Tower) SellValue() int {
+    return int(towerSellRefund*float64(t.spent))
+}
+
+// isBetterTarget reports whether the candidate enemy is a better target than the current best one,
+// according to the tower's targeting mode.
+func (t *Tower) isBetterTarget(candidate, best *Enemy, waypoints []Vec2) bool {
+    if best == nil {
+        return true
+    }
+    switch t.targetMode {
+    case targetLast:
+        return best.IsAheadOf(candidate, waypoints)
+    case targetStrongest:
+        if candidate.health != best.health {
+            return candidate.health > best.health
+        }
+        return candidate.IsAheadOf(best, waypoints)
+    case targetClosest:
+        candidateDist := candidate.position.Sub(t.position).Magnitude()
+        bestDist := best.position.Sub(t.position).Magnitude()
+        return candidateDist < bestDist
+    default:
+        return candidate.IsAheadOf(best, waypoints)
+    }
+}
+
+func (t *Tower) Update(g *GameState) {
     t.timeTillAttack -= deltaTime
     if t.currentTarget != nil {
         if (t.timeTillAttack <= 0.0) {
-            t.timeTillAttack = 1.5
-            createProjectile(t, t.currentTarget)
+            t.timeTillAttack = t.Cooldown()
+            g.createProjectile(t, t.currentTarget)
         }
         if t.currentTarget.health <= 0 {
             t.currentTarget = nil
@@ -67,24 +219,186 @@ func (t *Tower) Update() {
     }
 }
 
+// NOTE: Projectiles that haven't hit anything by the time they've flown this many times their
+//       tower's range have missed, and just disappear
+const projectileRangeScale = 2.0
+
 type Projectile struct {
     position Vec2
+    prevPosition Vec2 // NOTE: Where the projectile was on the previous tick, so rendering can interpolate
     scale float64
-    target *Enemy
+    size float64 // NOTE: The projectile's diameter
+    target *Enemy // NOTE: Only homing projectiles keep following this once they've been fired
     damage int
+    critical bool
     isDead bool
+    towerType int
+
+    speed float64
+    direction Vec2
+    aimPoint Vec2 // NOTE: Where area projectiles explode
+    distanceRemaining float64
+    hitsRemaining int
+    hitEnemies []*Enemy // NOTE: So that piercing projectiles don't hit the same enemy again on the next tick
+    hasHit bool
 
     rotation float64
 }
 
-func (p *Projectile) Update() {
-    speed := projectileSpeed * deltaTime
+func (p *Projectile) Type() *TowerType {
+    return &towerTypes[p.towerType]
+}
+
+func (p *Projectile) Radius() float64 {
+    return 0.5*p.size
+}
+
+// interceptPoint returns where a projectile fired from origin at the given speed should aim so that
+// it reaches the target at the same time as the target does, assuming it carries on along the path
+// at its current speed.
+func interceptPoint(origin Vec2, speed float64, target *Enemy, waypoints []Vec2) Vec2 {
+    // NOTE: The time to get there depends on where "there" is, so we just refine it a few times
+    result := target.position
+    for i := 0; i < 4; i++ {
+        flightTime := result.Sub(origin).Magnitude()/speed
+        result = target.PredictPosition(flightTime, waypoints)
+    }
+    return result
+}
+
+func (p *Projectile) Update(g *GameState) {
+    p.prevPosition = p.position
+    moveDist := p.speed*deltaTime
+    switch p.Type().projectileKind {
+    case projectileHoming:
+        p.updateHoming(g, moveDist)
+    case projectileArea:
+        p.updateArea(g, moveDist)
+    default:
+        p.updateStraight(g, moveDist)
+    }
+}
+
+func (p *Projectile) updateHoming(g *GameState, moveDist float64) {
+    if (p.target != nil) && (p.target.health <= 0) {
+        p.target = p.findNewTarget(g)
+    }
+    if p.target == nil {
+        p.updateStraight(g, moveDist)
+        return
+    }
+
     offset := p.target.position.Sub(p.position)
-    if offset.Magnitude() > speed {
-        p.rotation = math.Atan2(offset.y, offset.x)
-        p.position = p.position.Add(offset.Normalized().Mul(speed))
-    } else {
+    if offset.Magnitude() > moveDist {
+        p.direction = offset.Normalized()
+        p.rotation = math.Atan2(p.direction.y, p.direction.x)
+        p.position = p.position.Add(p.direction.Mul(moveDist))
+        return
+    }
+    p.isDead = true
+    p.hit(g, p.target)
+}
+
+// findNewTarget returns the closest living enemy to the projectile, if there's one within half of
+// its tower's range.
+func (p *Projectile) findNewTarget(g *GameState) *Enemy {
+    var result *Enemy
+    resultDist := 0.0
+    retargetRange := 0.5*p.Type().attackRange*g.params.TowerRangeScale*p.scale
+    g.nearbyEnemies = g.enemyGrid.Query(p.position, retargetRange, g.nearbyEnemies[:0])
+    for _,enemy := range g.nearbyEnemies {
+        if enemy.health <= 0 {
+            continue
+        }
+        dist := enemy.position.Sub(p.position).Magnitude()
+        if (result == nil) || (dist < resultDist) {
+            result = enemy
+            resultDist = dist
+        }
+    }
+    return result
+}
+
+// updateStraight moves the projectile in a straight line, hitting any enemies that it passes through.
+func (p *Projectile) updateStraight(g *GameState, moveDist float64) {
+    from := p.position
+    p.position = p.position.Add(p.direction.Mul(moveDist))
+    p.rotation = math.Atan2(p.direction.y, p.direction.x)
+    p.distanceRemaining -= moveDist
+
+    for _,enemy := range p.enemiesAlong(g, from, p.position) {
+        alreadyHit := false
+        for _,other := range p.hitEnemies {
+            alreadyHit = alreadyHit || (other == enemy)
+        }
+        if alreadyHit {
+            continue
+        }
+        p.hit(g, enemy)
+        p.hitEnemies = append(p.hitEnemies, enemy)
+        p.hitsRemaining--
+        if p.hitsRemaining <= 0 {
+            p.isDead = true
+            return
+        }
+    }
+    if p.distanceRemaining <= 0.0 {
         p.isDead = true
-        p.target.health -= p.damage
+    }
+}
+
+// enemiesAlong returns the living enemies that a projectile moving from one point to another would
+// touch, in the order that it would touch them.
+func (p *Projectile) enemiesAlong(g *GameState, from, to Vec2) []*Enemy {
+    maxEnemyRadius := 0.0
+    for index := range enemyTypes {
+        maxEnemyRadius = math.Max(maxEnemyRadius, 0.5*g.params.EnemySize*enemyTypes[index].size)
+    }
+    midpoint := from.Add(to).Mul(0.5)
+    queryRadius := 0.5*to.Sub(from).Magnitude() + p.Radius() + maxEnemyRadius
+    candidates := g.enemyGrid.Query(midpoint, queryRadius, g.nearbyEnemies[:0])
+
+    result := candidates[:0]
+    for _,enemy := range candidates {
+        if (enemy.health > 0) && (enemy.position.DistanceToSegment(from, to) < enemy.Radius() + p.Radius()) {
+            result = append(result, enemy)
+        }
+    }
+    sort.SliceStable(result, func(i, j int) bool {
+        return result[i].position.Sub(from).Dot(p.direction) < result[j].position.Sub(from).Dot(p.direction)
+    })
+    g.nearbyEnemies = result
+    return result
+}
+
+// updateArea moves the projectile straight towards its aim point, and then damages every enemy
+// around that point once it gets there.
+func (p *Projectile) updateArea(g *GameState, moveDist float64) {
+    offset := p.aimPoint.Sub(p.position)
+    if offset.Magnitude() > moveDist {
+        p.position = p.position.Add(p.direction.Mul(moveDist))
+        return
+    }
+
+    p.isDead = true
+    p.position = p.aimPoint
+    splashRadius := p.Type().splashRadius*p.scale
+    g.nearbyEnemies = g.enemyGrid.Query(p.aimPoint, splashRadius, g.nearbyEnemies[:0])
+    for _,enemy := range g.nearbyEnemies {
+        if enemy.health > 0 {
+            p.hit(g, enemy)
+        }
+    }
+}
+
+func (p *Projectile) hit(g *GameState, enemy *Enemy) {
+    projType := p.Type()
+    enemy.TakeDamage(p.damage)
+    for _,effect := range projType.effects {
+        enemy.ApplyEffect(effect.kind, effect.magnitude, effect.duration)
+    }
+    if !p.hasHit {
+        p.hasHit = true
+        g.waveStats.shotsHit++
     }
 }
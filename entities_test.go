@@ -0,0 +1,181 @@
+package main
+
+import "testing"
+
+// NOTE: These set the enemies and projectiles up by hand, a long way from the path, and only ever
+//       update the projectiles, so that nothing moves except what's being tested
+
+const testEnemyHealth = 20
+
+// placeEnemy puts a grunt that doesn't move at the given position.
+func placeEnemy(g *GameState, position Vec2) *Enemy {
+    enemy := g.newEnemy(enemyGrunt, position, 0)
+    enemy.health = testEnemyHealth
+    enemy.maxHealth = testEnemyHealth
+    enemy.speed = 0.0
+    g.enemies = append(g.enemies, enemy)
+    g.enemyGrid.Rebuild(g.enemies, g.enemyGridCellSize())
+    return enemy
+}
+
+// fire has a tower of the named type at the given position shoot at the target, and returns the projectile.
+func fire(t *testing.T, g *GameState, towerName string, position Vec2, target *Enemy) *Projectile {
+    t.Helper()
+    towerType, ok := towerTypeByName(towerName)
+    if !ok {
+        t.Fatalf("there's no %s tower", towerName)
+    }
+    tower := &Tower { position: position, scale: 1.0, rangeScale: 1.0, towerType: towerType }
+    g.createProjectile(tower, target)
+    return g.projectiles[len(g.projectiles)-1]
+}
+
+// fly updates the projectile until it's gone.
+func fly(t *testing.T, g *GameState, projectile *Projectile) {
+    t.Helper()
+    for ticks := 0; !projectile.isDead; ticks++ {
+        if ticks >= 60*10 {
+            t.Fatalf("the projectile was still flying after 10 seconds")
+        }
+        projectile.Update(g)
+    }
+}
+
+// checkDamaged checks that exactly the expected enemies took the projectile's damage.
+func checkDamaged(t *testing.T, projectile *Projectile, enemies []*Enemy, expected []bool) {
+    t.Helper()
+    for index,enemy := range enemies {
+        damaged := enemy.health < testEnemyHealth
+        if damaged != expected[index] {
+            t.Errorf("enemy %d should have been hit: %v, but it has %d health", index, expected[index], enemy.health)
+        } else if damaged && (enemy.health != testEnemyHealth-projectile.damage) {
+            t.Errorf("enemy %d took %d damage from a projectile that does %d", index, testEnemyHealth-enemy.health, projectile.damage)
+        }
+    }
+}
+
+func TestHomingProjectileFindsANewTarget(t *testing.T) {
+    g := newGameState(defaultGameParams())
+    origin := Vec2 { 1000.0, 1000.0 }
+    target := placeEnemy(g, origin.Add(Vec2 { 20.0, 0.0 }))
+    other := placeEnemy(g, origin.Add(Vec2 { 22.0, 3.0 }))
+    projectile := fire(t, g, "Basic", origin, target)
+    // NOTE: Close enough to the target that the enemy beside it is within the retargeting range
+    for projectile.position.Sub(target.position).Magnitude() > 10.0 {
+        projectile.Update(g)
+    }
+
+    target.health = 0
+    fly(t, g, projectile)
+    if projectile.target != other {
+        t.Errorf("the projectile didn't switch to the enemy next to its dead target")
+    }
+    if target.health != 0 {
+        t.Errorf("the dead target was hit anyway, and has %d health", target.health)
+    }
+    checkDamaged(t, projectile, []*Enemy { other }, []bool { true })
+    if g.waveStats.shotsHit != 1 {
+        t.Errorf("expected 1 shot to have hit, but got %d", g.waveStats.shotsHit)
+    }
+}
+
+func TestHomingProjectileWithNothingToRetargetMisses(t *testing.T) {
+    g := newGameState(defaultGameParams())
+    origin := Vec2 { 1000.0, 1000.0 }
+    target := placeEnemy(g, origin.Add(Vec2 { 20.0, 0.0 }))
+    // NOTE: Well out of the projectile's way, and too far from the target to be picked instead
+    bystander := placeEnemy(g, origin.Add(Vec2 { 0.0, 60.0 }))
+    projectile := fire(t, g, "Basic", origin, target)
+
+    target.health = 0
+    fly(t, g, projectile)
+    if bystander.health != testEnemyHealth {
+        t.Errorf("a projectile whose target died went on to hit an enemy far away")
+    }
+    if g.waveStats.shotsHit != 0 {
+        t.Errorf("a projectile that didn't hit anything counted as %d hits", g.waveStats.shotsHit)
+    }
+}
+
+func TestBallisticProjectileMissesAnEnemyThatMoved(t *testing.T) {
+    g := newGameState(defaultGameParams())
+    origin := Vec2 { 1000.0, 1000.0 }
+    target := placeEnemy(g, origin.Add(Vec2 { 20.0, 0.0 }))
+    projectile := fire(t, g, "Rapid", origin, target)
+
+    target.position = origin.Add(Vec2 { 0.0, 30.0 })
+    g.enemyGrid.Rebuild(g.enemies, g.enemyGridCellSize())
+    fly(t, g, projectile)
+    checkDamaged(t, projectile, []*Enemy { target }, []bool { false })
+    if projectile.position.Sub(origin).Magnitude() < projectileRangeScale*towerTypes[projectile.towerType].attackRange - 1.0 {
+        t.Errorf("the projectile stopped at %v, before it had flown its full range", projectile.position)
+    }
+    if g.waveStats.shotsHit != 0 {
+        t.Errorf("a projectile that missed counted as %d hits", g.waveStats.shotsHit)
+    }
+}
+
+func TestBallisticProjectileHitsTheFirstEnemyInTheWay(t *testing.T) {
+    g := newGameState(defaultGameParams())
+    origin := Vec2 { 1000.0, 1000.0 }
+    target := placeEnemy(g, origin.Add(Vec2 { 18.0, 0.0 }))
+    inTheWay := placeEnemy(g, origin.Add(Vec2 { 9.0, 0.0 }))
+    projectile := fire(t, g, "Rapid", origin, target)
+    fly(t, g, projectile)
+    checkDamaged(t, projectile, []*Enemy { inTheWay, target }, []bool { true, false })
+}
+
+func TestPiercingProjectileHitsUpToItsPierceCount(t *testing.T) {
+    g := newGameState(defaultGameParams())
+    origin := Vec2 { 1000.0, 1000.0 }
+    var line []*Enemy
+    for index := 0; index < 5; index++ {
+        line = append(line, placeEnemy(g, origin.Add(Vec2 { 15.0 + 12.0*float64(index), 0.0 })))
+    }
+    projectile := fire(t, g, "Sniper", origin, line[0])
+    if pierceCount := towerTypes[projectile.towerType].pierceCount; pierceCount != 3 {
+        t.Fatalf("the test expects the sniper to pierce 3 enemies, not %d", pierceCount)
+    }
+    fly(t, g, projectile)
+    checkDamaged(t, projectile, line, []bool { true, true, true, false, false })
+    if g.waveStats.shotsHit != 1 {
+        t.Errorf("a single piercing shot counted as %d hits", g.waveStats.shotsHit)
+    }
+}
+
+func TestAreaProjectileSplashesEverythingNearby(t *testing.T) {
+    g := newGameState(defaultGameParams())
+    origin := Vec2 { 1000.0, 1000.0 }
+    target := placeEnemy(g, origin.Add(Vec2 { 20.0, 0.0 }))
+    splashType, _ := towerTypeByName("Splash")
+    splashRadius := towerTypes[splashType].splashRadius
+    enemies := []*Enemy {
+        target,
+        placeEnemy(g, target.position.Add(Vec2 { 0.0, 0.8*splashRadius })),
+        placeEnemy(g, target.position.Add(Vec2 { -0.5*splashRadius, 0.0 })),
+        placeEnemy(g, target.position.Add(Vec2 { 0.0, 2.0*splashRadius })),
+    }
+    projectile := fire(t, g, "Splash", origin, target)
+    fly(t, g, projectile)
+    checkDamaged(t, projectile, enemies, []bool { true, true, true, false })
+    for index,enemy := range enemies[:3] {
+        if !enemy.HasEffect(effectBurn) {
+            t.Errorf("enemy %d was splashed, but wasn't set alight", index)
+        }
+    }
+}
+
+func TestTowerDropsADeadTarget(t *testing.T) {
+    g := newGameState(defaultGameParams())
+    origin := Vec2 { 1000.0, 1000.0 }
+    target := placeEnemy(g, origin.Add(Vec2 { 10.0, 0.0 }))
+    tower := &Tower { position: origin, scale: 1.0, rangeScale: 1.0, currentTarget: target, timeTillAttack: 1.0 }
+    target.health = 0
+    tower.Update(g)
+    if tower.currentTarget != nil {
+        t.Errorf("the tower kept its target after it died")
+    }
+    if len(g.projectiles) != 0 {
+        t.Errorf("the tower fired at its target even though it was still cooling down")
+    }
+}
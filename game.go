@@ -0,0 +1,847 @@
+package main
+
+import (
+    "fmt"
+    "math"
+    "strings"
+)
+
+const (
+    screenWidth  = 320
+    screenHeight = 240
+    aspectRatio = float64(screenWidth)/float64(screenHeight)
+    deltaTime = 1.0/60.0
+
+    towerAttackRange = 25.0 // NOTE: The range of the standard towers, before TowerRangeScale
+
+    spawnJitter = 0.25 // NOTE: How much the time in between spawns can vary by, either way
+    enemyMixChance = 0.15 // NOTE: The chance of each enemy in a wave swapping places with another one
+    criticalHitChance = 0.1
+    criticalHitMultiplier = 2
+
+    // NOTE: These are only the defaults, the game itself uses the values from its GameParams
+    defaultPathSegmentLength = 25.0
+    defaultPathWidth = 10.0
+    defaultTowerSize = 15.0
+    defaultProjectileSize = 4.0
+    defaultEnemySize = 10.0
+)
+
+// Input is everything the player can do during a single simulation tick.
+// NOTE: cursorLoc is in world-space, the renderer is responsible for mapping the mouse into it.
+type Input struct {
+    startWave bool
+    toggleGhost bool
+    restart bool
+    click bool
+    cursorLoc Vec2
+    selectTower int // NOTE: 1-based index into towerTypes, 0 leaves the current selection alone
+    upgrade bool
+    sell bool
+    cycleTargeting bool
+}
+
+// Merge combines the input from two frames into the input for a single tick, so that a key press or
+// click that happens in between ticks doesn't get lost. Anything that isn't a one-off press comes
+// from the later of the two.
+func (i Input) Merge(later Input) Input {
+    result := later
+    result.startWave = i.startWave || later.startWave
+    result.toggleGhost = i.toggleGhost || later.toggleGhost
+    result.restart = i.restart || later.restart
+    result.click = i.click || later.click
+    result.upgrade = i.upgrade || later.upgrade
+    result.sell = i.sell || later.sell
+    result.cycleTargeting = i.cycleTargeting || later.cycleTargeting
+    if later.selectTower == 0 {
+        result.selectTower = i.selectTower
+    }
+    return result
+}
+
+// GameParams are the starting values that Reset puts the game back to.
+// NOTE: These get written out alongside replays, so they need to be exported for encoding/json.
+type GameParams struct {
+    Lives int `json:"lives"`
+    Credits int `json:"credits"`
+    EnemySpeed float64 `json:"enemySpeed"`
+    EnemyHealth int `json:"enemyHealth"`
+    EnemyBounty int `json:"enemyBounty"`
+    ProjectileSpeed float64 `json:"projectileSpeed"`
+    Waves *WaveSet `json:"waves"`
+
+    PathGenerator string `json:"pathGenerator"`
+    PathDirections string `json:"pathDirections,omitempty"` // NOTE: Only used by the list path generator
+
+    Difficulty string `json:"difficulty"`
+    Modifiers []string `json:"modifiers,omitempty"`
+    Seed int64 `json:"seed"` // NOTE: Where the game's RNG starts, so the same seed always plays out the same way
+    AllowedTowers []string `json:"allowedTowers,omitempty"` // NOTE: The tower types that can be built, or all of them if empty
+    Daily string `json:"daily,omitempty"` // NOTE: The date of the daily challenge that these params are for, if any
+
+    PathSegmentLength float64 `json:"pathSegmentLength"`
+    PathWidth float64 `json:"pathWidth"`
+    TowerSize float64 `json:"towerSize"`
+    TowerRangeScale float64 `json:"towerRangeScale"` // NOTE: Multiplies the range of every type of tower
+    TowerCostScale float64 `json:"towerCostScale"` // NOTE: Multiplies the starting cost of every type of tower
+    ProjectileSize float64 `json:"projectileSize"`
+    EnemySize float64 `json:"enemySize"`
+}
+
+func defaultGameParams() GameParams {
+    return GameParams {
+        Lives: 10,
+        Credits: 2,
+        EnemySpeed: 15.0,
+        EnemyHealth: 1,
+        EnemyBounty: 1,
+        ProjectileSpeed: 300.0,
+        Waves: defaultWaveSet(),
+        PathGenerator: "dragon",
+        Difficulty: "normal",
+
+        PathSegmentLength: defaultPathSegmentLength,
+        PathWidth: defaultPathWidth,
+        TowerSize: defaultTowerSize,
+        TowerRangeScale: 1.0,
+        TowerCostScale: 1.0,
+        ProjectileSize: defaultProjectileSize,
+        EnemySize: defaultEnemySize,
+    }
+}
+
+// validate checks that the params can actually be used to start a game.
+func (p *GameParams) validate() error {
+    if p.Lives < 1 {
+        return fmt.Errorf("lives must be at least 1, not %d", p.Lives)
+    }
+    if p.Credits < 0 {
+        return fmt.Errorf("credits can't be negative, but it's %d", p.Credits)
+    }
+    if p.EnemyHealth < 1 {
+        return fmt.Errorf("enemyHealth must be at least 1, not %d", p.EnemyHealth)
+    }
+    if p.EnemyBounty < 0 {
+        return fmt.Errorf("enemyBounty can't be negative, but it's %d", p.EnemyBounty)
+    }
+    positive := []struct {
+        name string
+        value float64
+    } {
+        { "enemySpeed", p.EnemySpeed },
+        { "projectileSpeed", p.ProjectileSpeed },
+        { "pathSegmentLength", p.PathSegmentLength },
+        { "pathWidth", p.PathWidth },
+        { "towerSize", p.TowerSize },
+        { "towerRangeScale", p.TowerRangeScale },
+        { "towerCostScale", p.TowerCostScale },
+        { "projectileSize", p.ProjectileSize },
+        { "enemySize", p.EnemySize },
+    }
+    for _,param := range positive {
+        // NOTE: Written this way round so that NaN fails too
+        if !(param.value > 0.0) || math.IsInf(param.value, 0) {
+            return fmt.Errorf("%s must be a positive number, not %g", param.name, param.value)
+        }
+    }
+    if p.PathWidth >= p.PathSegmentLength {
+        return fmt.Errorf("pathWidth (%g) must be less than pathSegmentLength (%g), or the path would overlap itself",
+                          p.PathWidth, p.PathSegmentLength)
+    }
+    if err := p.validateDifficulty(); err != nil {
+        return err
+    }
+    for _,name := range p.AllowedTowers {
+        if _,ok := towerTypeByName(name); !ok {
+            return fmt.Errorf("unknown tower type %q, expected some of: %s", name, strings.Join(towerTypeNames(), ", "))
+        }
+    }
+    if p.Waves == nil {
+        return fmt.Errorf("no waves were given")
+    }
+    if err := p.Waves.validate(); err != nil {
+        return fmt.Errorf("invalid waves: %v", err)
+    }
+    if _,err := newPathGenerator(p, newRNG(p.Seed)); err != nil {
+        return err
+    }
+    return nil
+}
+
+// GameState holds the entire simulation. It never touches ebiten, so it can be stepped
+// without a window (or even a display) being available.
+type GameState struct {
+    params GameParams
+    camera Rect
+    rng RNG
+
+    pathBoundingBox Rect
+    pathEndLocation Vec2
+    pathGenerator PathGenerator
+
+    waypoints []Vec2
+    targetWaypointCount int
+    waypointSpawnInterval float64
+    timeTillNewWaypoint float64
+    waypointsReady bool
+
+    enemies []*Enemy
+    towers []*Tower
+    projectiles []*Projectile
+
+    enemyGrid *enemyGrid
+    nearbyEnemies []*Enemy // NOTE: Scratch space for enemyGrid queries, so they don't allocate every tick
+
+    enemySpeed float64
+    projectileSpeed float64
+
+    enemyHealth float64 // NOTE: Only rounded when an enemy spawns, so that the difficulty can scale small increases
+    enemyBounty int
+    waveSpawnQueue []waveSpawn
+
+    lives int
+    credits int
+    currentWave int
+    waveInProgress bool
+    waveEnemiesRemaining int
+    timeTillEnemySpawn float64
+
+    waveStats WaveStats // NOTE: Cleared at the start of every wave
+    runStats RunStats // NOTE: Only cleared by Reset, so it covers the whole game
+    difficulty *DifficultyPreset
+    modifiers [modifierCount]bool
+    events []GameEvent // NOTE: Everything noteworthy that happened during the last Step
+
+    ghostTowerVisible bool
+    ghostTower *Tower
+    towerCosts []int
+    selectedTower *Tower
+    displacedTowerCount int // NOTE: Towers that were removed (and refunded) because the path grew over them
+}
+
+// WaveStats counts what happened over the course of a single wave, for balancing the game.
+type WaveStats struct {
+    spawned int // NOTE: Including the enemies that split off from others
+    killed int
+    leaked int
+    creditsEarned int // NOTE: From bounties and the bonus for finishing the wave, not from selling towers
+    shotsFired int
+    shotsHit int
+}
+
+// RunStats counts what happened over the course of the whole game, for the high score table.
+type RunStats struct {
+    Killed int `json:"killed"`
+    TowersBuilt int `json:"towersBuilt"`
+    CreditsSpent int `json:"creditsSpent"` // NOTE: On building and upgrading towers
+    Ticks int `json:"ticks"` // NOTE: Only counts the ticks up until the game was lost
+}
+
+type GameEventKind int
+
+const (
+    eventTowerFired GameEventKind = iota
+    eventEnemyKilled
+    eventEnemyLeaked
+    eventWaveStarted
+    eventPathGrew
+
+    eventKindCount
+)
+
+// GameEvent is something that happened during a tick, which the simulation reports so that the
+// rendering side can react to it (e.g. by playing a sound) without having to compare states.
+type GameEvent struct {
+    kind GameEventKind
+}
+
+func (g *GameState) addEvent(kind GameEventKind) {
+    g.events = append(g.events, GameEvent { kind: kind })
+}
+
+func newGameState(params GameParams) *GameState {
+    g := &GameState {
+        params: params,
+        enemies: make([]*Enemy, 0),
+        towers: make([]*Tower, 0),
+        projectiles: make([]*Projectile, 0),
+        enemyGrid: newEnemyGrid(),
+        waypoints: make([]Vec2, 1),
+        ghostTower: &Tower{},
+        towerCosts: make([]int, len(towerTypes)),
+    }
+    g.Reset()
+    return g
+}
+
+func (g *GameState) Reset() {
+    g.difficulty = g.params.DifficultyPreset()
+    g.modifiers = [modifierCount]bool {}
+    for _,name := range g.params.Modifiers {
+        modifier, _ := modifierByName(name)
+        g.modifiers[modifier] = true
+    }
+
+    g.enemies = g.enemies[:0]
+    g.towers = g.towers[:0]
+    g.projectiles = g.projectiles[:0]
+    g.selectedTower = nil
+
+    g.projectileSpeed = g.params.ProjectileSpeed
+    g.enemySpeed = g.params.EnemySpeed
+    g.enemyBounty = g.params.EnemyBounty
+    g.enemyHealth = float64(g.params.EnemyHealth)
+    g.currentWave = 0
+    g.lives = scaleStart(g.params.Lives, g.difficulty.livesScale, 1)
+    g.waveInProgress = false
+    g.waveEnemiesRemaining = 0
+    g.waveStats = WaveStats {}
+    g.runStats = RunStats {}
+
+    cameraWidth := float64(screenWidth)
+    cameraHeight := float64(screenHeight)
+    g.camera = Rect {
+        position: Vec2 { cameraWidth/2.0, cameraHeight/2.0 },
+        size: Vec2 { float64(cameraWidth), float64(cameraHeight) },
+    }
+
+    g.credits = scaleStart(g.params.Credits, g.difficulty.creditsScale, 0)
+
+    g.ghostTowerVisible = true
+    g.ghostTower.scale = 1.0
+    g.ghostTower.rangeScale = g.params.TowerRangeScale
+    for i := range towerTypes {
+        g.towerCosts[i] = int(math.Max(1.0, math.Floor(g.params.TowerCostScale*float64(towerTypes[i].cost) + 0.5)))
+    }
+    g.selectTower(g.firstAllowedTower())
+
+    // NOTE: The path generator has to be the first thing that uses the RNG, see GameState.Load
+    g.rng = newRNG(g.params.Seed)
+    g.pathBoundingBox = Rect {}
+    g.pathEndLocation = Vec2 { 0.0, 0.0 }
+    g.pathGenerator = g.mustNewPathGenerator()
+    g.waypoints = g.waypoints[:1]
+    g.waypoints[0] = g.pathEndLocation
+    for i:=1; i<8; i++ {
+        g.addPathSegment()
+    }
+    g.waypointsReady = true
+}
+
+// allowsTower reports whether towers of the given type can be built with these params.
+func (p *GameParams) allowsTower(towerType int) bool {
+    if len(p.AllowedTowers) == 0 {
+        return true
+    }
+    for _,name := range p.AllowedTowers {
+        if strings.EqualFold(name, towerTypes[towerType].name) {
+            return true
+        }
+    }
+    return false
+}
+
+func (g *GameState) towerAllowed(towerType int) bool {
+    return g.params.allowsTower(towerType)
+}
+
+// firstAllowedTower returns the first tower type in the catalog that can be built, which there
+// always is at least one of once the params have been validated.
+func (g *GameState) firstAllowedTower() int {
+    for towerType := range towerTypes {
+        if g.towerAllowed(towerType) {
+            return towerType
+        }
+    }
+    return 0
+}
+
+func (g *GameState) selectTower(towerType int) {
+    g.ghostTower.towerType = towerType
+    g.ghostTower.cost = g.towerCosts[towerType]
+}
+
+// canStartWave reports whether the S key would currently start the next wave.
+func (g *GameState) canStartWave() bool {
+    return (len(g.enemies) == 0) && (g.waveEnemiesRemaining == 0) && (g.lives > 0) && g.waypointsReady
+}
+
+func (g *GameState) startRound() {
+    g.currentWave++
+    wave := g.params.Waves.Wave(g.currentWave)
+    g.enemySpeed *= scaleGrowth(wave.SpeedMultiplier, g.difficulty.enemySpeedGrowth)
+    g.projectileSpeed = g.enemySpeed*3.0
+    for i := range g.towerCosts {
+        g.towerCosts[i] = int(scaleGrowth(towerTypes[i].costGrowth, g.difficulty.towerCostGrowth) * float64(g.towerCosts[i]))
+    }
+    g.ghostTower.cost = g.towerCosts[g.ghostTower.towerType]
+
+    healthMultiplier := scaleGrowth(wave.HealthMultiplier, g.difficulty.enemyHealthGrowth)
+    healthIncrease := g.difficulty.enemyHealthGrowth*float64(wave.HealthIncrease)
+    g.enemyHealth = healthMultiplier*g.enemyHealth + healthIncrease
+    g.enemyBounty += wave.BountyIncrease
+
+    g.waveStats = WaveStats {}
+    g.waveSpawnQueue = wave.SpawnQueue()
+    // NOTE: Only the order gets mixed up, so the wave still has the enemies that the preview said it would
+    for i := range g.waveSpawnQueue {
+        if g.rng.Chance(enemyMixChance) {
+            j := g.rng.Intn(len(g.waveSpawnQueue))
+            g.waveSpawnQueue[i].enemyType, g.waveSpawnQueue[j].enemyType = g.waveSpawnQueue[j].enemyType, g.waveSpawnQueue[i].enemyType
+        }
+    }
+    g.waveEnemiesRemaining = len(g.waveSpawnQueue)
+    g.timeTillEnemySpawn = g.rng.Jitter(g.waveSpawnQueue[0].delay, spawnJitter)
+    g.waveInProgress = true
+    g.addEvent(eventWaveStarted)
+}
+
+func (g *GameState) endRound() {
+    g.waveInProgress = false
+    g.credits += g.currentWave
+    g.waveStats.creditsEarned += g.currentWave
+
+    g.targetWaypointCount = int(float64(len(g.waypoints))*g.difficulty.pathGrowth)
+    if g.hasModifier(modifierDoublePathGrowth) {
+        g.targetWaypointCount = int(float64(g.targetWaypointCount)*g.difficulty.pathGrowth)
+    }
+    newWaypointCount := g.targetWaypointCount - len(g.waypoints)
+    g.waypointSpawnInterval = 2.0/float64(newWaypointCount)
+    g.timeTillNewWaypoint = 0.0
+    g.waypointsReady = false
+}
+
+func (g *GameState) newEnemy(enemyType int, position Vec2, currentWaypoint int) *Enemy {
+    typeInfo := &enemyTypes[enemyType]
+    health := int(math.Max(1.0, math.Floor(typeInfo.healthScale*g.enemyHealth)))
+    // NOTE: Rounded up, so that a fractional bountyScale never makes an enemy worth nothing
+    bounty := int(math.Ceil(typeInfo.bountyScale*float64(g.enemyBounty)))
+    return &Enemy {
+        enemyType: enemyType,
+        size: g.params.EnemySize*typeInfo.size,
+        health: health,
+        maxHealth: health,
+        speed: typeInfo.speedScale*g.enemySpeed,
+        armour: typeInfo.armour,
+        bounty: bounty,
+        currentWaypoint: currentWaypoint,
+        position: position,
+        prevPosition: position,
+    }
+}
+
+func (g *GameState) nextSpawn() *waveSpawn {
+    return &g.waveSpawnQueue[len(g.waveSpawnQueue)-g.waveEnemiesRemaining]
+}
+
+func (g *GameState) sendEnemy() {
+    enemyType := g.nextSpawn().enemyType
+    g.enemies = append(g.enemies, g.newEnemy(enemyType, g.waypoints[0], 0))
+    g.waveStats.spawned++
+}
+
+func (g *GameState) addTower(loc Vec2) {
+    newTower := &Tower {
+        position: loc,
+        scale: g.ghostTower.scale,
+        rangeScale: g.params.TowerRangeScale,
+        towerType: g.ghostTower.towerType,
+        spent: g.ghostTower.cost,
+    }
+    g.towers = append(g.towers, newTower)
+}
+
+type PlacementResult int
+
+const (
+    placementOK PlacementResult = iota
+    placementNoCredits
+    placementPathGrowing
+    placementOnPath
+    placementOnTower
+)
+
+var placementResultNames = [...]string {
+    placementOK: "",
+    placementNoCredits: "Not enough credits",
+    placementPathGrowing: "Wait for the path to finish growing",
+    placementOnPath: "Can't build on the path",
+    placementOnTower: "Can't build on another tower",
+}
+
+func (r PlacementResult) String() string {
+    return placementResultNames[r]
+}
+
+// towerFootprintRadius is how much room a tower of the given scale takes up, which is what keeps it
+// off the path and away from other towers, and is also where it can be clicked on.
+// NOTE: It scales along with the tower, so that nothing can ever be built (or clicked to be built)
+//       anywhere that the tower being drawn there would overlap something
+func (g *GameState) towerFootprintRadius(scale float64) float64 {
+    return 0.3*g.params.TowerSize*scale
+}
+
+func (g *GameState) towerOverlapsSegment(position Vec2, scale float64, from, to Vec2) bool {
+    return position.DistanceToSegment(from, to) < 0.5*g.params.PathWidth + g.towerFootprintRadius(scale)
+}
+
+// checkPlacement reports whether the ghost tower can be built at the given location, and if not, why not.
+func (g *GameState) checkPlacement(loc Vec2) PlacementResult {
+    if g.credits < g.ghostTower.cost {
+        return placementNoCredits
+    }
+    return g.checkPlacementSite(loc)
+}
+
+// checkPlacementSite is checkPlacement without the check for whether the player can afford it.
+func (g *GameState) checkPlacementSite(loc Vec2) PlacementResult {
+    if !g.waypointsReady {
+        return placementPathGrowing
+    }
+    for i := 1; i < len(g.waypoints); i++ {
+        if g.towerOverlapsSegment(loc, g.ghostTower.scale, g.waypoints[i-1], g.waypoints[i]) {
+            return placementOnPath
+        }
+    }
+    for _,tower := range g.towers {
+        if loc.Sub(tower.position).Magnitude() < g.towerFootprintRadius(g.ghostTower.scale) + g.towerFootprintRadius(tower.scale) {
+            return placementOnTower
+        }
+    }
+    return placementOK
+}
+
+// removeTower removes the given tower without refunding anything for it.
+func (g *GameState) removeTower(tower *Tower) {
+    for index,other := range g.towers {
+        if other == tower {
+            copy(g.towers[index:], g.towers[index+1:])
+            g.towers[len(g.towers)-1] = nil
+            g.towers = g.towers[:len(g.towers)-1]
+            break
+        }
+    }
+    if g.selectedTower == tower {
+        g.selectedTower = nil
+    }
+}
+
+// towerAt returns the tower under the given world location, if there is one.
+func (g *GameState) towerAt(loc Vec2) *Tower {
+    for _,tower := range g.towers {
+        if loc.Sub(tower.position).Magnitude() < g.towerFootprintRadius(tower.scale) {
+            return tower
+        }
+    }
+    return nil
+}
+
+func (g *GameState) upgradeCost(tower *Tower) int {
+    if !tower.CanUpgrade() {
+        return 0
+    }
+    return int(towerUpgrades[tower.level].costFactor * float64(g.towerCosts[tower.towerType]))
+}
+
+func (g *GameState) upgradeTower(tower *Tower) {
+    if !tower.CanUpgrade() {
+        return
+    }
+    cost := g.upgradeCost(tower)
+    if g.credits < cost {
+        return
+    }
+    g.credits -= cost
+    g.runStats.CreditsSpent += cost
+    tower.spent += cost
+    tower.level++
+}
+
+func (g *GameState) sellTower(tower *Tower) {
+    g.credits += tower.SellValue()
+    g.removeTower(tower)
+}
+
+func (g *GameState) createProjectile(source *Tower, target *Enemy) {
+    projType := source.Type()
+    newProjectile := &Projectile {
+        position: source.position,
+        prevPosition: source.position,
+        scale: source.scale,
+        size: g.params.ProjectileSize*projType.projectileScale*source.scale,
+        target: target,
+        damage: source.Damage(),
+        towerType: source.towerType,
+        speed: g.projectileSpeed*projType.projectileSpeedScale,
+        distanceRemaining: projectileRangeScale*source.Range(),
+        hitsRemaining: 1,
+    }
+    if projType.projectileKind == projectilePiercing {
+        newProjectile.hitsRemaining = projType.pierceCount
+    }
+    if g.rng.Chance(criticalHitChance) {
+        newProjectile.damage *= criticalHitMultiplier
+        newProjectile.critical = true
+    }
+    // NOTE: Homing projectiles just point at the target, the rest aim for where it's going to be
+    newProjectile.aimPoint = target.position
+    if projType.projectileKind != projectileHoming {
+        newProjectile.aimPoint = interceptPoint(source.position, newProjectile.speed, target, g.waypoints)
+    }
+    aimOffset := newProjectile.aimPoint.Sub(source.position)
+    newProjectile.direction = Vec2 { 1.0, 0.0 }
+    if aimOffset.Magnitude() > 0.0 {
+        newProjectile.direction = aimOffset.Normalized()
+    }
+    newProjectile.rotation = math.Atan2(newProjectile.direction.y, newProjectile.direction.x)
+    g.projectiles = append(g.projectiles, newProjectile)
+    g.waveStats.shotsFired++
+    g.addEvent(eventTowerFired)
+}
+
+func (g *GameState) resizeCameraToContainRect(r Rect) {
+    minSize := r.size.Add(Vec2{ 50, 50 })
+    xScaleFactor := minSize.x/g.camera.size.x
+    yScaleFactor := minSize.y/g.camera.size.y
+    scaleFactor := math.Max(xScaleFactor, yScaleFactor)
+    if g.currentWave > 0 {
+        // NOTE: We only scale after the first round so that we can just set the size to be what we
+        //       want it to look like at the beginning, and then it'll scale from there
+        g.ghostTower.scale *= scaleFactor
+    }
+
+    g.camera.position = r.position
+    g.camera.size = g.camera.size.Mul(scaleFactor)
+}
+
+// mustNewPathGenerator creates the path generator for the current params.
+// NOTE: Params are always validated before a game gets created with them, so this can't fail
+func (g *GameState) mustNewPathGenerator() PathGenerator {
+    result, err := newPathGenerator(&g.params, g.rng.Split())
+    if err != nil {
+        panic(err)
+    }
+    return result
+}
+
+func (g *GameState) addPathSegment() {
+    direction := g.pathGenerator.NextDirection()
+    segmentStart := g.pathEndLocation
+    g.pathEndLocation = g.pathEndLocation.Add(direction.Mul(g.params.PathSegmentLength))
+    g.waypoints = append(g.waypoints, g.pathEndLocation)
+
+    // NOTE: It's not the player's fault that the path grew over their towers, so they get everything back
+    for index := 0; index < len(g.towers); {
+        tower := g.towers[index]
+        if g.towerOverlapsSegment(tower.position, tower.scale, segmentStart, g.pathEndLocation) {
+            g.credits += tower.spent
+            g.displacedTowerCount++
+            g.removeTower(tower)
+        } else {
+            index++
+        }
+    }
+
+    if !g.pathBoundingBox.ContainsPoint(g.pathEndLocation) {
+        minX := math.Min(g.pathEndLocation.x, g.pathBoundingBox.MinX())
+        maxX := math.Max(g.pathEndLocation.x, g.pathBoundingBox.MaxX())
+        minY := math.Min(g.pathEndLocation.y, g.pathBoundingBox.MinY())
+        maxY := math.Max(g.pathEndLocation.y, g.pathBoundingBox.MaxY())
+
+        g.pathBoundingBox.position.x = (minX+maxX)/2.0
+        g.pathBoundingBox.position.y = (minY+maxY)/2.0
+        g.pathBoundingBox.size.x = maxX-minX
+        g.pathBoundingBox.size.y = maxY-minY
+
+        g.resizeCameraToContainRect(g.pathBoundingBox)
+    }
+}
+
+// Step advances the simulation by a single tick of deltaTime, applying the given input first.
+func (g *GameState) Step(input Input) {
+    g.events = g.events[:0]
+    if input.restart && (g.lives == 0) {
+        // NOTE: Otherwise every restart would play out exactly the same as the last game did, but
+        //       that's the whole point of the daily challenge
+        if g.params.Daily == "" {
+            g.params.Seed = g.rng.PickSeed()
+        }
+        g.Reset()
+    }
+    // NOTE: After restarting, so that the tick a game was restarted on counts the same as the first
+    //       tick of a new game, and a replay of just the new game gives the same score
+    if g.lives > 0 {
+        g.runStats.Ticks++
+    }
+
+    if input.startWave && g.canStartWave() {
+        g.startRound()
+    }
+
+    if input.toggleGhost {
+        g.ghostTowerVisible = !g.ghostTowerVisible
+    }
+
+    if (input.selectTower > 0) && (input.selectTower <= len(towerTypes)) && g.towerAllowed(input.selectTower-1) {
+        g.selectTower(input.selectTower-1)
+    }
+
+    g.ghostTower.position = input.cursorLoc
+
+    if input.click {
+        clickedTower := g.towerAt(input.cursorLoc)
+        if clickedTower != nil {
+            if clickedTower == g.selectedTower {
+                g.selectedTower = nil
+            } else {
+                g.selectedTower = clickedTower
+            }
+        } else if g.selectedTower != nil {
+            g.selectedTower = nil
+        } else if g.ghostTowerVisible {
+            if g.checkPlacement(g.ghostTower.position) == placementOK {
+                g.addTower(g.ghostTower.position)
+                g.credits -= g.ghostTower.cost
+                g.runStats.TowersBuilt++
+                g.runStats.CreditsSpent += g.ghostTower.cost
+            }
+        } else {
+            g.ghostTowerVisible = true
+        }
+    }
+
+    if g.selectedTower != nil {
+        if input.upgrade {
+            g.upgradeTower(g.selectedTower)
+        }
+        if input.cycleTargeting {
+            g.selectedTower.targetMode = (g.selectedTower.targetMode+1) % targetModeCount
+        }
+        if input.sell && !g.hasModifier(modifierNoSelling) {
+            g.sellTower(g.selectedTower)
+        }
+    }
+
+    if g.waveEnemiesRemaining > 0 {
+        g.timeTillEnemySpawn -= deltaTime
+        for (g.timeTillEnemySpawn < 0.0) && (g.waveEnemiesRemaining > 0) {
+            g.sendEnemy()
+            g.waveEnemiesRemaining--
+            if g.waveEnemiesRemaining > 0 {
+                g.timeTillEnemySpawn += g.rng.Jitter(g.nextSpawn().delay, spawnJitter)
+            }
+        }
+    }
+
+    if !g.waypointsReady && (len(g.waypoints) < g.targetWaypointCount) {
+        g.timeTillNewWaypoint -= deltaTime
+        // NOTE: Once the path is long enough, more than one segment can be due in a single tick
+        for (g.timeTillNewWaypoint <= 0.0) && (len(g.waypoints) < g.targetWaypointCount) {
+            g.addPathSegment()
+            g.addEvent(eventPathGrew)
+            g.timeTillNewWaypoint += g.waypointSpawnInterval
+        }
+        if len(g.waypoints) >= g.targetWaypointCount {
+            g.waypointsReady = true
+        }
+    }
+
+    var spawnedEnemies []*Enemy
+    enemies := g.enemies
+    for index,enemy := range enemies {
+        if enemy == nil {
+            continue
+        }
+        enemy.Update(g)
+        if enemy.health <= 0 {
+            g.credits += enemy.bounty
+            g.waveStats.killed++
+            g.runStats.Killed++
+            g.addEvent(eventEnemyKilled)
+            g.waveStats.creditsEarned += enemy.bounty
+            enemyType := enemy.Type()
+            for i := 0; i < enemyType.splitCount; i++ {
+                child := g.newEnemy(enemyType.splitInto, enemy.position, enemy.currentWaypoint)
+                if (i > 0) && (enemy.currentWaypoint > 0) {
+                    // NOTE: Stagger the children back along the path so they don't all sit on top of each other
+                    backOffset := g.waypoints[enemy.currentWaypoint-1].Sub(enemy.position)
+                    backDist := math.Min(backOffset.Magnitude(), float64(i)*0.5*g.params.EnemySize)
+                    if backDist > 0.0 {
+                        child.position = child.position.Add(backOffset.Normalized().Mul(backDist))
+                        child.prevPosition = child.position
+                    }
+                }
+                spawnedEnemies = append(spawnedEnemies, child)
+                g.waveStats.spawned++
+            }
+            g.removeEnemy(index)
+            continue
+        }
+        if enemy.currentWaypoint == len(g.waypoints) {
+            g.lives--
+            g.waveStats.leaked++
+            g.addEvent(eventEnemyLeaked)
+            if g.lives == 0 {
+                g.waveInProgress = false
+            } else if g.lives < 0 {
+                g.lives = 0
+            }
+            enemy.health = -1
+            g.removeEnemy(index)
+            continue
+        }
+    }
+    g.enemies = append(g.enemies, spawnedEnemies...)
+    g.enemyGrid.Rebuild(g.enemies, g.enemyGridCellSize())
+    for _,tower := range g.towers {
+        // NOTE: We re-pick the target every tick, so that a tower doesn't keep wasting shots on an
+        //       enemy that is about to leave its range when there is a better one available
+        g.acquireTarget(tower)
+        tower.Update(g)
+    }
+    projectiles := g.projectiles
+    for index,projectile := range projectiles {
+        if projectile == nil {
+            continue // NOTE: I mean this works, but is there a less-hacky way of removing inside a range?
+        }
+        projectile.Update(g)
+        if projectile.isDead {
+            g.projectiles[index] = g.projectiles[len(g.projectiles)-1]
+            g.projectiles[len(g.projectiles)-1] = nil
+            g.projectiles = g.projectiles[:len(g.projectiles)-1]
+            continue
+        }
+    }
+
+    if g.waveInProgress && (len(g.enemies) == 0) && (g.waveEnemiesRemaining == 0) && (g.lives > 0) {
+        g.endRound()
+    }
+}
+
+// acquireTarget points the tower at the best enemy within its range, or at nothing if there isn't one.
+// NOTE: This relies on enemyGrid having been rebuilt since the enemies last moved
+func (g *GameState) acquireTarget(tower *Tower) {
+    tower.currentTarget = nil
+    g.nearbyEnemies = g.enemyGrid.Query(tower.position, tower.Range(), g.nearbyEnemies[:0])
+    for _,enemy := range g.nearbyEnemies {
+        if tower.isBetterTarget(enemy, tower.currentTarget, g.waypoints) {
+            tower.currentTarget = enemy
+        }
+    }
+}
+
+// enemyGridCellSize picks a cell size for the enemy grid that is about the range of a new basic tower.
+// NOTE: Towers (and their ranges) get scaled up as the path grows, so a fixed cell size would have
+//       the later towers looking through hundreds of cells for every query.
+func (g *GameState) enemyGridCellSize() float64 {
+    return math.Max(g.params.PathSegmentLength, towerAttackRange*g.params.TowerRangeScale*g.ghostTower.scale)
+}
+
+// removeEnemy swaps the enemy at the given index with the last one and shrinks the slice.
+// NOTE: This leaves a nil behind in any slice header that was taken before the removal, which is
+//       why the update loops above range over a copy of the header and skip nil entries.
+func (g *GameState) removeEnemy(index int) {
+    g.enemies[index] = g.enemies[len(g.enemies)-1]
+    g.enemies[len(g.enemies)-1] = nil
+    g.enemies = g.enemies[:len(g.enemies)-1]
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "testing"
+)
+
+// maxWaveTicks is far longer than any of the early waves take, so a test that hits it has hung.
+const maxWaveTicks = 60*60*5
+
+// playWave starts the next wave and steps the game until it's over, either because every enemy
+// has been dealt with or because the game was lost.
+func playWave(t *testing.T, g *GameState) {
+    t.Helper()
+    if !g.canStartWave() {
+        t.Fatalf("wave %d can't be started", g.currentWave+1)
+    }
+    g.Step(Input { startWave: true })
+    for ticks := 0; g.waveInProgress || !g.waypointsReady; ticks++ {
+        if ticks >= maxWaveTicks {
+            t.Fatalf("wave %d still hadn't finished after %d ticks", g.currentWave, ticks)
+        }
+        g.Step(Input {})
+    }
+}
+
+// findBuildSite returns the free spot closest to the given waypoint that the ghost tower could be
+// built on, searching outwards in small steps.
+func findBuildSite(t *testing.T, g *GameState, waypoint int) Vec2 {
+    t.Helper()
+    centre := g.waypoints[waypoint]
+    best := Vec2 {}
+    bestDist := -1.0
+    step := 0.5
+    for y := -2.0*g.params.PathSegmentLength; y <= 2.0*g.params.PathSegmentLength; y += step {
+        for x := -2.0*g.params.PathSegmentLength; x <= 2.0*g.params.PathSegmentLength; x += step {
+            loc := centre.Add(Vec2 { x, y })
+            if g.checkPlacementSite(loc) != placementOK {
+                continue
+            }
+            dist := loc.Sub(centre).Magnitude()
+            if (bestDist < 0.0) || (dist < bestDist) {
+                best = loc
+                bestDist = dist
+            }
+        }
+    }
+    if bestDist < 0.0 {
+        t.Fatalf("there's nowhere to build near waypoint %d", waypoint)
+    }
+    return best
+}
+
+// build clicks to build the currently selected tower type at the given location.
+func build(t *testing.T, g *GameState, loc Vec2) {
+    t.Helper()
+    towerCount := len(g.towers)
+    g.Step(Input { click: true, cursorLoc: loc })
+    if len(g.towers) != towerCount+1 {
+        t.Fatalf("clicking at %v didn't build a tower (%v)", loc, g.checkPlacement(loc))
+    }
+}
+
+// snapshot describes everything about the game that two runs of it should agree on, in a form
+// that's easy to compare and print when they don't.
+// NOTE: The save has everything but the enemies and projectiles, which we add on at the end
+func snapshot(t *testing.T, g *GameState) string {
+    t.Helper()
+    data, err := json.Marshal(g.Save())
+    if err != nil {
+        t.Fatalf("failed to encode the game: %v", err)
+    }
+    result := string(data)
+    for _,enemy := range g.enemies {
+        result += fmt.Sprintf("\nenemy %d at %v with %d health, %d effects", enemy.enemyType, enemy.position, enemy.health, len(enemy.effects))
+    }
+    for _,projectile := range g.projectiles {
+        result += fmt.Sprintf("\nprojectile %d at %v for %d damage", projectile.towerType, projectile.position, projectile.damage)
+    }
+    return result
+}
+
+func TestWaveLeaksWithoutTowers(t *testing.T) {
+    g := newGameState(defaultGameParams())
+    startLives := g.lives
+    startCredits := g.credits
+
+    playWave(t, g)
+    if g.currentWave != 1 {
+        t.Fatalf("expected to be on wave 1, but got %d", g.currentWave)
+    }
+    if g.waveStats.spawned == 0 {
+        t.Fatalf("the first wave didn't spawn any enemies")
+    }
+    if (g.waveStats.killed != 0) || (g.waveStats.leaked != g.waveStats.spawned) {
+        t.Errorf("with no towers all %d enemies should leak, but %d were killed and %d leaked",
+                 g.waveStats.spawned, g.waveStats.killed, g.waveStats.leaked)
+    }
+    if g.lives != startLives-g.waveStats.leaked {
+        t.Errorf("expected %d lives after %d leaks, but got %d", startLives-g.waveStats.leaked, g.waveStats.leaked, g.lives)
+    }
+    if g.credits != startCredits+1 {
+        t.Errorf("expected the wave 1 bonus to bring the credits to %d, but got %d", startCredits+1, g.credits)
+    }
+    if len(g.waypoints) <= 8 {
+        t.Errorf("the path should have grown after the wave, but it still has %d waypoints", len(g.waypoints))
+    }
+}
+
+func TestTowerDefendsFirstWave(t *testing.T) {
+    params := defaultGameParams()
+    params.Credits = 10
+    g := newGameState(params)
+    startLives := g.lives
+    for _,waypoint := range []int { 2, 4, 6 } {
+        build(t, g, findBuildSite(t, g, waypoint))
+    }
+    if g.runStats.CreditsSpent != params.Credits-g.credits {
+        t.Errorf("%d credits were spent on towers, but %d were counted", params.Credits-g.credits, g.runStats.CreditsSpent)
+    }
+
+    playWave(t, g)
+    if g.waveStats.killed == 0 {
+        t.Fatalf("the towers didn't kill anything (%+v)", g.waveStats)
+    }
+    if g.lives != startLives-g.waveStats.leaked {
+        t.Errorf("expected %d lives after %d leaks, but got %d", startLives-g.waveStats.leaked, g.waveStats.leaked, g.lives)
+    }
+    if g.waveStats.shotsHit > g.waveStats.shotsFired {
+        t.Errorf("%d shots hit, but only %d were fired", g.waveStats.shotsHit, g.waveStats.shotsFired)
+    }
+    if g.runStats.Killed != g.waveStats.killed {
+        t.Errorf("the run counted %d kills, but the wave counted %d", g.runStats.Killed, g.waveStats.killed)
+    }
+    if g.runStats.TowersBuilt != 3 {
+        t.Errorf("expected 3 towers to have been built, but got %+v", g.runStats)
+    }
+}
+
+func TestLostGameRestarts(t *testing.T) {
+    params := defaultGameParams()
+    params.Lives = 1
+    g := newGameState(params)
+    playWave(t, g)
+    if g.lives != 0 {
+        t.Fatalf("expected the game to be lost with no towers and 1 life, but it has %d lives", g.lives)
+    }
+    if g.canStartWave() {
+        t.Errorf("a wave can be started after the game was lost")
+    }
+    g.Step(Input { restart: true })
+    if (g.lives != 1) || (g.currentWave != 0) || (len(g.enemies) != 0) {
+        t.Errorf("restarting didn't reset the game: %d lives on wave %d with %d enemies", g.lives, g.currentWave, len(g.enemies))
+    }
+}
+
+func TestEveryEnemyIsWorthSomething(t *testing.T) {
+    g := newGameState(defaultGameParams())
+    for enemyType := range enemyTypes {
+        if enemy := g.newEnemy(enemyType, Vec2 {}, 0); enemy.bounty < 1 {
+            t.Errorf("%s enemies are worth %d credits with an enemyBounty of %d", enemyTypes[enemyType].name, enemy.bounty, g.enemyBounty)
+        }
+    }
+}
+
+// NOTE: Towers built later in the game are scaled up, so the new tower is tried at a few scales
+func TestAdjacentPlacement(t *testing.T) {
+    for _,ghostScale := range []float64 { 1.0, 1.5, 2.0 } {
+        params := defaultGameParams()
+        params.Credits = 100
+        g := newGameState(params)
+        site := findBuildSite(t, g, 3)
+        build(t, g, site)
+        existing := g.towers[0]
+        g.ghostTower.scale = ghostScale
+        gap := g.towerFootprintRadius(existing.scale) + g.towerFootprintRadius(ghostScale)
+
+        found := false
+        for angle := 0; (angle < 360) && !found; angle += 5 {
+            direction := directionFromDegrees(angle)
+            loc := site.Add(direction.Mul(gap + 0.01))
+            if g.checkPlacementSite(loc) != placementOK {
+                continue
+            }
+            found = true
+            if tooClose := site.Add(direction.Mul(gap - 0.01)); g.checkPlacementSite(tooClose) == placementOK {
+                t.Errorf("at scale %g, a tower can be built overlapping the one at %v", ghostScale, site)
+            }
+            if g.towerAt(loc) != nil {
+                t.Errorf("at scale %g, clicking on a spot that a tower can be built on would select another tower", ghostScale)
+            }
+            build(t, g, loc)
+        }
+        if !found {
+            t.Fatalf("at scale %g, there was nowhere to build right next to the tower at %v", ghostScale, site)
+        }
+
+        g.Step(Input { click: true, cursorLoc: site.Add(Vec2 { 0.9*g.towerFootprintRadius(existing.scale), 0.0 }) })
+        if g.selectedTower != existing {
+            t.Errorf("at scale %g, clicking just inside a tower's footprint didn't select it", ghostScale)
+        }
+    }
+}
+
+// NOTE: A frame that doesn't run a tick has its input merged into the next one's, so no press can get lost
+func TestMergeKeepsOneOffPresses(t *testing.T) {
+    presses := []Input {
+        { startWave: true },
+        { toggleGhost: true },
+        { restart: true },
+        { click: true },
+        { upgrade: true },
+        { sell: true },
+        { cycleTargeting: true },
+        { selectTower: 2 },
+    }
+    for _,press := range presses {
+        expected := press
+        expected.cursorLoc = Vec2 { 3.0, 4.0 }
+        if merged := press.Merge(Input { cursorLoc: expected.cursorLoc }); merged != expected {
+            t.Errorf("merging %+v with the next frame lost the press, giving %+v", press, merged)
+        }
+        if merged := (Input {}).Merge(press); merged != press {
+            t.Errorf("merging the next frame's %+v lost the press, giving %+v", press, merged)
+        }
+    }
+}
@@ -0,0 +1,77 @@
+// +build headless
+
+package main
+
+import (
+    "flag"
+    "fmt"
+    "log"
+    "os"
+    "strings"
+)
+
+// NOTE: This is the entry point when building with `go build -tags headless`, which leaves out
+//       everything that needs ebiten (and so a display) and just runs the simulation.
+func main() {
+    paramFlags := addParamFlags()
+    strategyName := flag.String("strategy", "path", "How the simulated player places towers: " + strings.Join(strategyNames(), ", "))
+    strategySeed := flag.Int64("strategy-seed", 0, "The seed for strategies that make random choices")
+    waveCount := flag.Int("wave-count", 20, "The number of waves to simulate")
+    regress := flag.Bool("regress", false, "Play the bot games in the regression baseline, and fail if any of them went differently than expected")
+    regressUpdate := flag.Bool("regress-update", false, "Play the bot games in the regression baseline, and record how they went as the new baseline")
+    regressBaseline := flag.String("regress-baseline", "regression.json", "The regression baseline file")
+    bench := flag.Bool("bench", false, "Measure how fast the simulation runs with a late-game number of enemies and towers")
+    benchWave := flag.Int("bench-wave", 30, "The wave whose enemy count the benchmark should use")
+    benchTowers := flag.Int("bench-towers", 60, "The number of towers to place for the benchmark")
+    benchTicks := flag.Int("bench-ticks", 600, "The number of ticks to run each part of the benchmark for")
+    verifyDaily := flag.String("verify-daily", "", "Check the daily challenge result at the given path by playing back its replay")
+    flag.Parse()
+
+    if *verifyDaily != "" {
+        result, err := loadDailyResult(*verifyDaily)
+        if err != nil {
+            log.Fatal(err)
+        }
+        if err := result.Verify(); err != nil {
+            log.Fatalf("The daily challenge result %s is not valid: %v", *verifyDaily, err)
+        }
+        score := &result.Score
+        fmt.Printf("Verified the daily challenge for %s: wave %d, %d kills, %d towers built and %d credits spent\n",
+                   result.Date, score.Wave, score.Killed, score.TowersBuilt, score.CreditsSpent)
+        return
+    }
+
+    if *bench {
+        runBenchmark(*benchWave, *benchTowers, *benchTicks)
+        return
+    }
+
+    if *regress || *regressUpdate {
+        baseline, err := loadRegressionBaseline(*regressBaseline)
+        if err != nil {
+            log.Fatal(err)
+        }
+        passed := runRegression(baseline, *regressUpdate, os.Stdout)
+        if *regressUpdate {
+            if err := baseline.WriteFile(*regressBaseline); err != nil {
+                log.Fatal(err)
+            }
+        } else if !passed {
+            os.Exit(1)
+        }
+        return
+    }
+
+    params, err := paramFlags.Params()
+    if err != nil {
+        log.Fatal(err)
+    }
+    strategy, err := newStrategy(*strategyName, *strategySeed)
+    if err != nil {
+        log.Fatal(err)
+    }
+    result := runSimulation(params, strategy, *waveCount, os.Stdout)
+    if result.stuck {
+        os.Exit(1)
+    }
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "hash/fnv"
+    "io/ioutil"
+    "os"
+    "time"
+)
+
+const highScoresVersion = 1
+
+// NOTE: How many scores each table keeps, any worse than that just don't make it in
+const highScoreTableSize = 8
+
+// HighScore is a single finished game, as it's remembered in the high score table.
+type HighScore struct {
+    Wave int `json:"wave"`
+    Killed int `json:"killed"`
+    TowersBuilt int `json:"towersBuilt"`
+    CreditsSpent int `json:"creditsSpent"`
+    Duration float64 `json:"duration"` // NOTE: In seconds of game time, so it doesn't depend on the game speed
+    Seed int64 `json:"seed"` // NOTE: So that the same game can be played again with -seed
+    Modifiers []string `json:"modifiers,omitempty"`
+    Date string `json:"date"`
+}
+
+// HighScoreTable is the best games for a single difficulty and path generator, best first. Each
+// day's daily challenge gets a table of its own, and so does each set of tuned params.
+// NOTE: The modifiers aren't part of the key, there would be far too many tables if they were
+type HighScoreTable struct {
+    Difficulty string `json:"difficulty"`
+    PathGenerator string `json:"pathGenerator"`
+    Daily string `json:"daily,omitempty"`
+    Tuning string `json:"tuning,omitempty"` // NOTE: A hash of the params that were changed from the defaults, if any were
+    Scores []HighScore `json:"scores"`
+}
+
+type HighScores struct {
+    Version int `json:"version"`
+    Tables []HighScoreTable `json:"tables"`
+}
+
+// HighScore returns the score for the game so far, which is only final once the game has been lost.
+func (g *GameState) HighScore(date time.Time) HighScore {
+    return HighScore {
+        Wave: g.currentWave,
+        Killed: g.runStats.Killed,
+        TowersBuilt: g.runStats.TowersBuilt,
+        CreditsSpent: g.runStats.CreditsSpent,
+        Duration: float64(g.runStats.Ticks)*deltaTime,
+        Seed: g.params.Seed,
+        Modifiers: append([]string(nil), g.params.Modifiers...),
+        Date: date.Format("2006-01-02"),
+    }
+}
+
+// betterThan ranks scores by the wave that they got to, then by kills, and then by whoever got
+// there the quickest.
+func (s *HighScore) betterThan(other *HighScore) bool {
+    if s.Wave != other.Wave {
+        return s.Wave > other.Wave
+    }
+    if s.Killed != other.Killed {
+        return s.Killed > other.Killed
+    }
+    return s.Duration < other.Duration
+}
+
+func (t *HighScoreTable) Label() string {
+    if t.Daily != "" {
+        return "Daily " + t.Daily
+    }
+    if t.Tuning != "" {
+        return fmt.Sprintf("%s, %s path, tuned %s", difficultyTitle(t.Difficulty), t.PathGenerator, t.Tuning)
+    }
+    return fmt.Sprintf("%s, %s path", difficultyTitle(t.Difficulty), t.PathGenerator)
+}
+
+// tuningHash returns a short hash of the params, or an empty string if they're the defaults, so that
+// games with more lives or cheaper towers (say) don't end up in the same table as everyone else's.
+// NOTE: The params that already pick a table, or that vary from game to game, are left out of it
+func tuningHash(params *GameParams) string {
+    if params.Daily != "" {
+        return ""
+    }
+    tuning := func(p GameParams) []byte {
+        p.Difficulty = ""
+        p.PathGenerator = ""
+        p.Modifiers = nil
+        p.Seed = 0
+        // NOTE: This only fails on NaNs and infinities, which validate has already turned away
+        data, _ := json.Marshal(p)
+        return data
+    }
+    tuned := tuning(*params)
+    if bytes.Equal(tuned, tuning(defaultGameParams())) {
+        return ""
+    }
+    hash := fnv.New32a()
+    hash.Write(tuned)
+    return fmt.Sprintf("%08x", hash.Sum32())
+}
+
+// Text lays out the first count scores in the table, with a marker next to the highlighted one.
+func (t *HighScoreTable) Text(count int, highlight int) string {
+    if len(t.Scores) == 0 {
+        return "No scores yet"
+    }
+    result := "   # Wave Kills Towers Spent  Time"
+    for index := 0; (index < count) && (index < len(t.Scores)); index++ {
+        score := &t.Scores[index]
+        marker := "  "
+        if index == highlight {
+            marker = "> "
+        }
+        minutes := int(score.Duration)/60
+        seconds := int(score.Duration)%60
+        result += fmt.Sprintf("\n%s%2d %4d %5d %6d %5d %2d:%02d", marker, index+1, score.Wave, score.Killed,
+                              score.TowersBuilt, score.CreditsSpent, minutes, seconds)
+    }
+    return result
+}
+
+// Table returns the table for the given params, or nil if there are no scores for them yet.
+func (h *HighScores) Table(params *GameParams) *HighScoreTable {
+    tuning := tuningHash(params)
+    for index := range h.Tables {
+        table := &h.Tables[index]
+        if (table.Difficulty == params.Difficulty) && (table.PathGenerator == params.PathGenerator) &&
+           (table.Daily == params.Daily) && (table.Tuning == tuning) {
+            return table
+        }
+    }
+    return nil
+}
+
+// Add puts the score into the table for the given params, and returns where it ranks in that table,
+// or -1 if it wasn't good enough to make it in.
+func (h *HighScores) Add(params *GameParams, score HighScore) int {
+    table := h.Table(params)
+    if table == nil {
+        h.Tables = append(h.Tables, HighScoreTable {
+            Difficulty: params.Difficulty,
+            PathGenerator: params.PathGenerator,
+            Daily: params.Daily,
+            Tuning: tuningHash(params),
+        })
+        table = &h.Tables[len(h.Tables)-1]
+    }
+
+    rank := len(table.Scores)
+    for index := range table.Scores {
+        if score.betterThan(&table.Scores[index]) {
+            rank = index
+            break
+        }
+    }
+    if rank >= highScoreTableSize {
+        return -1
+    }
+    table.Scores = append(table.Scores, HighScore {})
+    copy(table.Scores[rank+1:], table.Scores[rank:])
+    table.Scores[rank] = score
+    if len(table.Scores) > highScoreTableSize {
+        table.Scores = table.Scores[:highScoreTableSize]
+    }
+    return rank
+}
+
+func (h *HighScores) WriteFile(path string) error {
+    data, err := json.MarshalIndent(h, "", "    ")
+    if err != nil {
+        return err
+    }
+    return ioutil.WriteFile(path, data, 0644)
+}
+
+// loadHighScores reads the high score file, or returns an empty set of tables if there isn't one yet.
+func loadHighScores(path string) (*HighScores, error) {
+    result := &HighScores { Version: highScoresVersion }
+    data, err := ioutil.ReadFile(path)
+    if os.IsNotExist(err) {
+        return result, nil
+    } else if err != nil {
+        return nil, err
+    }
+
+    if err := json.Unmarshal(data, result); err != nil {
+        return nil, fmt.Errorf("failed to parse high scores %s: %v", path, err)
+    }
+    if result.Version != highScoresVersion {
+        return nil, fmt.Errorf("high scores %s have version %d, but only version %d is supported",
+                               path, result.Version, highScoresVersion)
+    }
+    return result, nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestTunedGamesGetTheirOwnTable(t *testing.T) {
+    scores := &HighScores { Version: highScoresVersion }
+    defaults := defaultGameParams()
+    scores.Add(&defaults, HighScore { Wave: 5 })
+
+    // NOTE: The seed and modifiers change from game to game, so they share the table
+    varied := defaultGameParams()
+    varied.Seed = 1234
+    varied.Modifiers = []string { modifierNames[0] }
+    if scores.Table(&varied) != scores.Table(&defaults) {
+        t.Errorf("a game with a different seed and modifiers got a table of its own")
+    }
+
+    tuned := defaultGameParams()
+    tuned.Lives = 100
+    if table := scores.Table(&tuned); table != nil {
+        t.Fatalf("a game with %d lives would go in the %q table", tuned.Lives, table.Label())
+    }
+    if rank := scores.Add(&tuned, HighScore { Wave: 3 }); rank != 0 {
+        t.Errorf("the first score in a new table should rank first, not %d", rank)
+    }
+    table := scores.Table(&tuned)
+    if (table == nil) || (table.Tuning == "") || (table.Label() == scores.Table(&defaults).Label()) {
+        t.Fatalf("the tuned game's table can't be told apart from the default one: %+v", table)
+    }
+    if len(scores.Table(&defaults).Scores) != 1 {
+        t.Errorf("the tuned game's score went into the default table")
+    }
+
+    daily, err := dailyParams("2026-01-01")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if tuning := tuningHash(&daily); tuning != "" {
+        t.Errorf("the daily challenge is keyed on its date, but it also got tuning %q", tuning)
+    }
+}
@@ -0,0 +1,407 @@
+// +build !headless
+
+package main
+
+import (
+    "bytes"
+    "fmt"
+    "image"
+    "math"
+    "strings"
+
+    "github.com/hajimehoshi/ebiten"
+    "github.com/jacquesh/LD38-InDefenseOfADragon/ui"
+)
+
+const (
+    hudBarHeight = 17.0
+    hudPaletteY = screenHeight - 22.0
+    hudButtonHeight = 18.0
+    hudPaletteButtonWidth = 39.0
+    hudRowHeight = 13.0
+    hudHighScoreCount = 5 // NOTE: How much of the high score table fits on the game over screen
+)
+
+// HUD is all of the widgets that get drawn over the game. They're built once, and then refresh
+// updates their text and which of them are visible to match the current state of the game.
+type HUD struct {
+    root ui.Root
+    menuRoot ui.Root // NOTE: Separate so that it can be drawn on top of the overlay that darkens everything else
+
+    livesLabel *ui.Label
+    creditsLabel *ui.Label
+    waveLabel *ui.Label
+
+    palette *ui.Panel
+    towerButtons []*ui.Button
+    startWaveButton *ui.Button
+
+    preview *ui.Panel
+    previewTitle *ui.Label
+    previewWaveLabel *ui.Label
+    previewIcons []*ui.Icon
+    previewCounts []*ui.Label
+
+    towerPanel *ui.Panel
+    towerTitle *ui.Label
+    towerStats *ui.Label
+    upgradeButton *ui.Button
+    targetingButton *ui.Button
+    sellButton *ui.Button
+
+    gameOver *ui.Panel
+    gameOverLabel *ui.Label
+    restartButton *ui.Button
+
+    help *ui.Panel
+    helpLabel *ui.Label
+    helpShown bool // NOTE: Toggled with H, and hidden as soon as a wave starts
+
+    statusLabel *ui.Label
+    placementLabel *ui.Label
+
+    menuPanel *ui.Panel
+    menuLabel *ui.Label
+
+    pressed Input // NOTE: Whatever the buttons that got clicked this frame want to do
+}
+
+const helpText =
+    "Click: build or select a tower\n" +
+    "1-6/buttons: choose a tower\n" +
+    "G: toggle the build cursor\n" +
+    "Space: start the next wave\n" +
+    "U/T/X: upgrade/target/sell\n" +
+    "Wheel/WASD/right-drag: view\n" +
+    "F: fit view, F1-F4: game speed\n" +
+    "P: pause, F5/F9: save/load\n" +
+    "M: mute the sound\n" +
+    "Esc: quit, H: hide this help"
+
+func loadFont(path string) (*ui.Font, error) {
+    data, err := Asset(path)
+    if err != nil {
+        return nil, err
+    }
+    atlas, _, err := image.Decode(bytes.NewReader(data))
+    if err != nil {
+        return nil, err
+    }
+    return ui.NewFont(atlas, 7, 13)
+}
+
+func newHUD(font *ui.Font) *HUD {
+    h := &HUD { helpShown: true }
+    theme := ui.Theme {
+        Font: font,
+        Pixel: pixelImg,
+        Text: ui.RGBA(1.0, 1.0, 1.0, 1.0),
+        DisabledText: ui.RGBA(0.5, 0.5, 0.5, 1.0),
+        Panel: ui.RGBA(0.1, 0.1, 0.15, 0.8),
+        Button: ui.RGBA(0.2, 0.2, 0.3, 1.0),
+        ButtonHovered: ui.RGBA(0.3, 0.3, 0.45, 1.0),
+        ButtonSelected: ui.RGBA(0.35, 0.45, 0.25, 1.0),
+        Border: ui.RGBA(0.5, 0.5, 0.6, 1.0),
+        Tooltip: ui.RGBA(0.05, 0.05, 0.1, 0.95),
+    }
+    h.root.Theme = theme
+    h.menuRoot.Theme = theme
+
+    h.livesLabel = &ui.Label { Base: ui.Base { Rect: ui.NewRect(4, 2, 70, 13) } }
+    h.creditsLabel = &ui.Label { Base: ui.Base { Rect: ui.NewRect(74, 2, 90, 13) } }
+    h.waveLabel = &ui.Label { Base: ui.Base { Rect: ui.NewRect(164, 2, 152, 13) }, AlignRight: true }
+    topBar := &ui.Panel {
+        Base: ui.Base { Rect: ui.NewRect(0, 0, screenWidth, hudBarHeight) },
+        Children: []ui.Widget { h.livesLabel, h.creditsLabel, h.waveLabel },
+    }
+
+    h.palette = &ui.Panel { Base: ui.Base { Rect: ui.NewRect(0, hudPaletteY, screenWidth, screenHeight-hudPaletteY) } }
+    for index := range towerTypes {
+        towerIndex := index
+        button := &ui.Button {
+            Base: ui.Base { Rect: ui.NewRect(2 + float64(index)*(hudPaletteButtonWidth+1), hudPaletteY+2, hudPaletteButtonWidth, hudButtonHeight) },
+            Icon: towerImg[0],
+            IconTint: towerTypeColor(&towerTypes[index]),
+            OnClick: func() {
+                h.pressed.selectTower = towerIndex+1
+                if !game.ghostTowerVisible {
+                    h.pressed.toggleGhost = true
+                }
+            },
+        }
+        h.towerButtons = append(h.towerButtons, button)
+        h.palette.Children = append(h.palette.Children, button)
+    }
+    startX := 2 + float64(len(towerTypes))*(hudPaletteButtonWidth+1)
+    h.startWaveButton = &ui.Button {
+        Base: ui.Base { Rect: ui.NewRect(startX, hudPaletteY+2, screenWidth-2-startX, hudButtonHeight) },
+        OnClick: func() { h.pressed.startWave = true },
+    }
+    h.palette.Children = append(h.palette.Children, h.startWaveButton)
+
+    h.previewTitle = &ui.Label { Base: ui.Base { Rect: ui.NewRect(240, hudBarHeight+5, 76, 13) } }
+    h.previewWaveLabel = &ui.Label { Color: ui.RGBA(1.0, 0.8, 0.4, 1.0) }
+    h.preview = &ui.Panel { Children: []ui.Widget { h.previewTitle, h.previewWaveLabel } }
+    for index := range enemyTypes {
+        icon := &ui.Icon {
+            Image: enemyImg[enemyTypes[index].frames[0]],
+            Tint: ebiten.ScaleColor(enemyTypes[index].tint[0], enemyTypes[index].tint[1], enemyTypes[index].tint[2], 1.0),
+        }
+        count := &ui.Label {}
+        h.previewIcons = append(h.previewIcons, icon)
+        h.previewCounts = append(h.previewCounts, count)
+        h.preview.Children = append(h.preview.Children, icon, count)
+    }
+
+    h.towerTitle = &ui.Label { Base: ui.Base { Rect: ui.NewRect(5, hudBarHeight+5, 100, 13) } }
+    h.towerStats = &ui.Label { Base: ui.Base { Rect: ui.NewRect(5, hudBarHeight+18, 100, 13) } }
+    h.upgradeButton = &ui.Button {
+        Base: ui.Base { Rect: ui.NewRect(4, hudBarHeight+33, 104, 16), Tooltip: "Upgrade the tower (U)" },
+        OnClick: func() { h.pressed.upgrade = true },
+    }
+    h.targetingButton = &ui.Button {
+        Base: ui.Base { Rect: ui.NewRect(4, hudBarHeight+51, 104, 16), Tooltip: "Change which enemy it shoots at (T)" },
+        OnClick: func() { h.pressed.cycleTargeting = true },
+    }
+    h.sellButton = &ui.Button {
+        Base: ui.Base { Rect: ui.NewRect(4, hudBarHeight+69, 104, 16), Tooltip: "Sell the tower (X)" },
+        OnClick: func() { h.pressed.sell = true },
+    }
+    h.towerPanel = &ui.Panel {
+        Base: ui.Base { Rect: ui.NewRect(2, hudBarHeight+3, 108, 85) },
+        Children: []ui.Widget { h.towerTitle, h.towerStats, h.upgradeButton, h.targetingButton, h.sellButton },
+    }
+
+    h.helpLabel = &ui.Label { Base: ui.Base { Rect: ui.NewRect(5, hudBarHeight+6, 224, 143) } }
+    h.help = &ui.Panel {
+        Base: ui.Base { Rect: ui.NewRect(2, hudBarHeight+3, 230, 149) },
+        Children: []ui.Widget { h.helpLabel },
+    }
+
+    h.statusLabel = &ui.Label { Base: ui.Base { Rect: ui.NewRect(4, hudPaletteY-15, 312, 13), MouseTransparent: true } }
+    h.placementLabel = &ui.Label {
+        Base: ui.Base { Rect: ui.NewRect(4, hudPaletteY-28, 312, 13), MouseTransparent: true },
+        Color: ui.RGBA(1.0, 0.5, 0.5, 1.0),
+    }
+
+    // NOTE: The game over panel gets sized to fit its text in refresh
+    h.gameOverLabel = &ui.Label {}
+    h.restartButton = &ui.Button {
+        Text: "Restart (R)",
+        OnClick: func() { h.pressed.restart = true },
+    }
+    h.gameOver = &ui.Panel { Children: []ui.Widget { h.gameOverLabel, h.restartButton } }
+
+    h.root.Children = []ui.Widget {
+        topBar, h.palette, h.preview, h.help, h.towerPanel, h.statusLabel, h.placementLabel, h.gameOver,
+    }
+
+    h.menuLabel = &ui.Label { Base: ui.Base { Rect: ui.NewRect(26, 34, 268, 172) } }
+    h.menuPanel = &ui.Panel {
+        Base: ui.Base { Rect: ui.NewRect(20, 28, 280, 184) },
+        Children: []ui.Widget { h.menuLabel },
+    }
+    h.menuRoot.Children = []ui.Widget { h.menuPanel }
+    return h
+}
+
+func effectDescription(effect EffectApplication) string {
+    name := statusEffectTypes[effect.kind].name
+    switch effect.kind {
+    case effectSlow:
+        return fmt.Sprintf("%s to %.0f%% for %gs", name, 100.0*effect.magnitude, effect.duration)
+    case effectBurn, effectPoison:
+        return fmt.Sprintf("%s %g/s for %gs", name, effect.magnitude, effect.duration)
+    case effectArmourShred:
+        return fmt.Sprintf("%s %g for %gs", name, effect.magnitude, effect.duration)
+    }
+    return fmt.Sprintf("%s for %gs", name, effect.duration)
+}
+
+var projectileKindDescriptions = [...]string {
+    projectileHoming: "Homing shots",
+    projectileBallistic: "Fast shots that can miss",
+    projectilePiercing: "Shots pierce %d enemies",
+    projectileArea: "Shots hit an area",
+}
+
+func towerTypeDescription(towerType int) string {
+    info := &towerTypes[towerType]
+    lines := []string {
+        fmt.Sprintf("%s tower (%d)", info.name, towerType+1),
+        fmt.Sprintf("Damage %d every %gs", info.damage, info.cooldown),
+        fmt.Sprintf("Range %.0f", info.attackRange*game.params.TowerRangeScale),
+    }
+    if info.projectileKind == projectilePiercing {
+        lines = append(lines, fmt.Sprintf(projectileKindDescriptions[info.projectileKind], info.pierceCount))
+    } else {
+        lines = append(lines, projectileKindDescriptions[info.projectileKind])
+    }
+    for _,effect := range info.effects {
+        lines = append(lines, effectDescription(effect))
+    }
+    return strings.Join(lines, "\n")
+}
+
+// Update hit-tests the mouse against the HUD, and clicks whatever it's over. Anything that the
+// buttons do gets added to the input, and clicks on the HUD don't also go through to the game.
+// It returns whether the mouse is over the HUD.
+func (h *HUD) Update(input *Input, menuOpen bool) bool {
+    if menuOpen {
+        h.root.Update(-1.0, -1.0, false)
+        return false
+    }
+
+    if keyJustPressed(ebiten.KeyH) {
+        h.helpShown = !h.helpShown
+    }
+    cursor := cursorScreenLoc()
+    h.pressed = Input {}
+    overUI := h.root.Update(cursor.x, cursor.y, input.click)
+    if overUI {
+        input.click = false
+    }
+    pressed := h.pressed
+    pressed.cursorLoc = input.cursorLoc
+    pressed.restart = pressed.restart || input.restart
+    *input = input.Merge(pressed)
+    if input.startWave {
+        h.helpShown = false
+    }
+    return overUI
+}
+
+// refresh brings the widgets up to date with the current state of the game.
+func (h *HUD) refresh(placement PlacementResult, showPlacement bool, menuOpen bool) {
+    lost := game.lives == 0
+    betweenWaves := (len(game.enemies) == 0) && (game.waveEnemiesRemaining == 0)
+
+    h.livesLabel.Text = fmt.Sprintf("Lives %d", game.lives)
+    h.creditsLabel.Text = fmt.Sprintf("Credits %d", game.credits)
+    h.waveLabel.Text = fmt.Sprintf("Wave %d", game.currentWave)
+    if gameSpeeds[gameSpeedIndex] == 0.0 {
+        h.waveLabel.Text = "Paused  " + h.waveLabel.Text
+    } else if gameSpeeds[gameSpeedIndex] != 1.0 {
+        h.waveLabel.Text = fmt.Sprintf("%gx  %s", gameSpeeds[gameSpeedIndex], h.waveLabel.Text)
+    }
+
+    h.palette.Hidden = lost
+    for index,button := range h.towerButtons {
+        button.Text = fmt.Sprint(game.towerCosts[index])
+        button.Tooltip = towerTypeDescription(index)
+        button.Disabled = !game.towerAllowed(index)
+        if button.Disabled {
+            button.Tooltip += "\nNot allowed in this game"
+        }
+        button.Selected = game.ghostTowerVisible && (game.ghostTower.towerType == index)
+    }
+    nextWave := game.currentWave+1
+    h.startWaveButton.Text = fmt.Sprintf("Start %d", nextWave)
+    h.startWaveButton.Tooltip = fmt.Sprintf("Start wave %d (Space)", nextWave)
+    h.startWaveButton.Disabled = !game.canStartWave()
+
+    h.preview.Hidden = lost || !betweenWaves
+    if !h.preview.Hidden {
+        h.refreshPreview(nextWave)
+    }
+
+    selected := game.selectedTower
+    h.towerPanel.Hidden = lost || (selected == nil)
+    if selected != nil {
+        h.towerTitle.Text = fmt.Sprintf("%s %d/%d", selected.Type().name, selected.level, len(towerUpgrades))
+        h.towerStats.Text = fmt.Sprintf("Dmg %d Rng %.0f", selected.Damage(), selected.Range())
+        if selected.CanUpgrade() {
+            h.upgradeButton.Text = fmt.Sprintf("Upgrade %d", game.upgradeCost(selected))
+            h.upgradeButton.Disabled = game.credits < game.upgradeCost(selected)
+        } else {
+            h.upgradeButton.Text = "Fully upgraded"
+            h.upgradeButton.Disabled = true
+        }
+        h.targetingButton.Text = "Aim: " + selected.targetMode.String()
+        h.sellButton.Text = fmt.Sprintf("Sell +%d", selected.SellValue())
+        h.sellButton.Disabled = game.hasModifier(modifierNoSelling)
+        if h.sellButton.Disabled {
+            h.sellButton.Text = "No selling"
+        }
+    }
+
+    h.help.Hidden = lost || !h.helpShown || (selected != nil)
+    h.helpLabel.Text = fmt.Sprintf("Seed %d (use with -seed)\n", game.params.Seed) + helpText
+
+    h.statusLabel.Hidden = statusMsgTimeRemaining <= 0.0
+    h.statusLabel.Text = statusMsg
+    h.placementLabel.Hidden = lost || !showPlacement || (placement == placementOK)
+    h.placementLabel.Text = placement.String()
+
+    h.gameOver.Hidden = !lost
+    if lost {
+        difficulty := strings.Replace(game.params.DifficultyLabel(), ", ", "\n", -1)
+        h.gameOverLabel.Text = fmt.Sprintf("You lost on wave %d :(\n\n%s\nSeed %d", game.currentWave, difficulty, game.params.Seed)
+        if game.params.Daily != "" {
+            h.gameOverLabel.Text = fmt.Sprintf("You lost on wave %d :(\n\nDaily challenge %s\n%s", game.currentWave, game.params.Daily, difficulty)
+        }
+        if highScoreRank >= 0 {
+            h.gameOverLabel.Text += fmt.Sprintf("\n\nNew high score, #%d!", highScoreRank+1)
+        }
+        if table := highScores.Table(&game.params); table != nil {
+            h.gameOverLabel.Text += "\n\n" + table.Text(hudHighScoreCount, highScoreRank)
+        }
+        width, height := h.root.Theme.Font.Measure(h.gameOverLabel.Text)
+        width = math.Max(width, 100.0)
+        panel := ui.NewRect(0.5*(screenWidth-width) - 6, 0.5*(screenHeight-height) - 20, width+12, height+36)
+        h.gameOver.Rect = panel
+        h.gameOverLabel.Rect = ui.NewRect(panel.X+6, panel.Y+6, width, height)
+        h.restartButton.Rect = ui.NewRect(0.5*screenWidth - 50, panel.Y+height+12, 100, hudButtonHeight)
+    }
+
+    h.menuPanel.Hidden = !menuOpen
+    if menuOpen {
+        h.menuLabel.Text = menuText()
+    }
+}
+
+// refreshPreview lists how many of each type of enemy are coming in the given wave.
+func (h *HUD) refreshPreview(wave int) {
+    definition := game.params.Waves.Wave(wave)
+    h.previewTitle.Text = fmt.Sprintf("Wave %d", wave)
+    y := h.previewTitle.Rect.Y + hudRowHeight
+    h.previewWaveLabel.Hidden = definition.Label == ""
+    if definition.Label != "" {
+        h.previewWaveLabel.Text = definition.Label
+        if len(h.previewWaveLabel.Text) > 10 {
+            h.previewWaveLabel.Text = h.previewWaveLabel.Text[:10]
+        }
+        h.previewWaveLabel.Rect = ui.NewRect(h.previewTitle.Rect.X, y, 76, hudRowHeight)
+        y += hudRowHeight
+    }
+
+    counts := make([]int, len(enemyTypes))
+    for _,spawn := range definition.SpawnQueue() {
+        counts[spawn.enemyType]++
+    }
+    y += 2
+    for index,count := range counts {
+        h.previewIcons[index].Hidden = count == 0
+        h.previewCounts[index].Hidden = count == 0
+        if count == 0 {
+            continue
+        }
+        h.previewIcons[index].Rect = ui.NewRect(h.previewTitle.Rect.X, y, 12, 12)
+        h.previewIcons[index].Tooltip = enemyTypes[index].name
+        h.previewCounts[index].Rect = ui.NewRect(h.previewTitle.Rect.X+16, y, 60, hudRowHeight)
+        h.previewCounts[index].Text = fmt.Sprintf("x%d", count)
+        y += hudRowHeight+1
+    }
+    h.preview.Rect = ui.NewRect(h.previewTitle.Rect.X-4, hudBarHeight+3, 84, y+3 - (hudBarHeight+3))
+}
+
+func (h *HUD) Draw(screen *ebiten.Image, menuOpen bool) {
+    h.root.Draw(screen)
+    if menuOpen {
+        opts := ebiten.DrawImageOptions{}
+        opts.GeoM.Scale(screenWidth, screenHeight)
+        opts.ColorM = ebiten.ScaleColor(0,0,0,0.6)
+        screen.DrawImage(pixelImg, &opts)
+        h.menuRoot.Draw(screen)
+    }
+}
@@ -1,35 +1,21 @@
+// +build !headless
+
 package main
 
 import (
     "bytes"
-    "fmt"
+    "flag"
     "image"
     _ "image/png"
     "image/color"
     "log"
     "math"
-    "os"
+    "time"
 
     "github.com/hajimehoshi/ebiten"
-    "github.com/hajimehoshi/ebiten/ebitenutil"
-)
-
-const (
-    screenWidth  = 320
-    screenHeight = 240
-    aspectRatio = float64(screenWidth)/float64(screenHeight)
-    deltaTime = 1.0/60.0
-
-    pathSegmentLength = 25.0
-    towerSize = 15.0
-    towerAttackRange = 25.0
-    projectileSize = 4.0
-    enemySize = 10.0
 )
 
 var (
-    camera Rect
-
     pixelImg *ebiten.Image
     circleImg *ebiten.Image
     backgroundImg *ebiten.Image
@@ -43,46 +29,37 @@ var (
     pathEndImg *ebiten.Image
     enemyImg [6]*ebiten.Image
 
-    pathBoundingBox Rect
-    pathEndDirection Vec2
-    pathEndLocation Vec2
-    pathEndIndex int
-
-    waypoints []Vec2
-    targetWaypointCount int
-    waypointSpawnInterval float64
-    timeTillNewWaypoint float64
-    waypointsReady bool
-
-    enemies []*Enemy
-    towers []*Tower
-    projectiles []*Projectile
-
-    enemySpeed float64
-    projectileSpeed float64
-
-    enemyHealth int
-    enemiesPerWave int
-    enemySpawnInterval float64
-    enemyBounty int
-
-    lives int
-    credits int
-    currentWave int
-    waveInProgress bool
-    waveEnemiesRemaining int
-    timeTillEnemySpawn float64
+    game *GameState
+    viewCamera *ViewCamera
+    hud *HUD
+    gameAudio *Mixer
+    recorder *ReplayRecorder
+    dailyRecorder *DailyRecorder // NOTE: Only in the daily challenge, whether or not there's a -record too
+    replayPlayer *ReplayPlayer
+    recordPath string
+    dailyResultPath string
+    savePath string
+    highScores *HighScores
+    highScoresPath string
+    highScoreRank = -1 // NOTE: Where the last game that was lost placed in its high score table
+
+    statusMsg string
+    statusMsgTimeRemaining float64
 
     blackoutOpacity float64
 
-    ghostTowerVisible bool
-    ghostTower *Tower
+    lastFrameTime time.Time
+    tickAccumulator float64
+    gameSpeedIndex = 1
+    pendingInput Input
 
     keyWasDown [ebiten.KeyMax]bool
     mousePressed [3]bool
+    dragScreenLoc Vec2
 )
 
 func screen2WorldLoc(screenLoc Vec2) Vec2 {
+    camera := &viewCamera.view
     result := screenLoc
     result.x *= camera.size.x/screenWidth
     result.y *= camera.size.y/screenHeight
@@ -91,6 +68,7 @@ func screen2WorldLoc(screenLoc Vec2) Vec2 {
 }
 
 func world2ScreenLoc(worldLoc Vec2) Vec2 {
+    camera := &viewCamera.view
     result := worldLoc
     result = result.Sub(camera.MinXY())
     result.x *= screenWidth/camera.size.x;
@@ -98,373 +76,369 @@ func world2ScreenLoc(worldLoc Vec2) Vec2 {
     return result
 }
 
-func startRound() {
-    currentWave++
-    enemiesPerWave += currentWave
-    enemySpawnInterval = 10.0/float64(enemiesPerWave)
-    enemySpeed *= 1.8
-    projectileSpeed = enemySpeed*3.0
-    ghostTower.cost = int(1.5 * float64(ghostTower.cost))
+func keyJustPressed(key ebiten.Key) bool {
+    pressed := ebiten.IsKeyPressed(key)
+    result := pressed && !keyWasDown[key]
+    keyWasDown[key] = pressed
+    return result
+}
 
-    if currentWave%2 == 1 {
-        enemyHealth += 1
+// anyKeyJustPressed reports whether any of the given keys were just pressed.
+// NOTE: Unlike chaining keyJustPressed with ||, this checks every one of the keys, since each of
+//       them has to be checked every frame to keep its edge-detection up to date
+func anyKeyJustPressed(keys ...ebiten.Key) bool {
+    result := false
+    for _,key := range keys {
+        if keyJustPressed(key) {
+            result = true
+        }
     }
-    if currentWave%4 == 1 {
-        enemyBounty += 1
+    return result
+}
+
+var towerSelectKeys = [...]ebiten.Key {
+    ebiten.Key1, ebiten.Key2, ebiten.Key3, ebiten.Key4, ebiten.Key5, ebiten.Key6,
+}
+
+func pollInput() Input {
+    input := Input {
+        startWave: keyJustPressed(ebiten.KeySpace),
+        toggleGhost: keyJustPressed(ebiten.KeyG),
+        restart: keyJustPressed(ebiten.KeyR),
+        upgrade: keyJustPressed(ebiten.KeyU),
+        sell: keyJustPressed(ebiten.KeyX),
+        cycleTargeting: keyJustPressed(ebiten.KeyT),
     }
 
-    waveEnemiesRemaining = enemiesPerWave
-    timeTillEnemySpawn = 0.0
-    waveInProgress = true
+    for index,key := range towerSelectKeys {
+        if keyJustPressed(key) && (index < len(towerTypes)) {
+            input.selectTower = index+1
+        }
+    }
+
+    input.cursorLoc = screen2WorldLoc(cursorScreenLoc())
+
+    leftPressed := ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+    input.click = leftPressed && !mousePressed[ebiten.MouseButtonLeft]
+    mousePressed[ebiten.MouseButtonLeft] = leftPressed
+
+    return input
 }
 
-func endRound() {
-    waveInProgress = false
-    credits += currentWave
+func cursorScreenLoc() Vec2 {
+    mouseX, mouseY := ebiten.CursorPosition()
+    return Vec2 { float64(mouseX), float64(mouseY) }
+}
 
-    targetWaypointCount = int(float64(len(waypoints))*1.6)
-    newWaypointCount := targetWaypointCount - len(waypoints)
-    waypointSpawnInterval = 2.0/float64(newWaypointCount)
-    timeTillNewWaypoint = 0.0
-    waypointsReady = false
+// NOTE: W/A/S/D and the arrow keys pan the view, and the right mouse button drags it around
+var cameraPanKeys = [...]struct {
+    key ebiten.Key
+    direction Vec2
+} {
+    { ebiten.KeyW, Vec2 { 0.0, -1.0 } },
+    { ebiten.KeyA, Vec2 { -1.0, 0.0 } },
+    { ebiten.KeyS, Vec2 { 0.0, 1.0 } },
+    { ebiten.KeyD, Vec2 { 1.0, 0.0 } },
+    { ebiten.KeyUp, Vec2 { 0.0, -1.0 } },
+    { ebiten.KeyLeft, Vec2 { -1.0, 0.0 } },
+    { ebiten.KeyDown, Vec2 { 0.0, 1.0 } },
+    { ebiten.KeyRight, Vec2 { 1.0, 0.0 } },
 }
 
-func sendEnemy() {
-    newEnemy := &Enemy {
-        health: enemyHealth,
-        currentWaypoint: 0,
-        position: waypoints[0],
+func updateViewCamera(frameTime float64) {
+    for _,pan := range cameraPanKeys {
+        if ebiten.IsKeyPressed(pan.key) {
+            viewCamera.Pan(pan.direction.Mul(cameraPanSpeed*viewCamera.view.size.x*frameTime))
+        }
     }
-    enemies = append(enemies, newEnemy)
-}
 
-func addTower(loc Vec2) {
-    newTower := &Tower {
-        position: loc,
-        scale: ghostTower.scale,
+    screenLoc := cursorScreenLoc()
+    rightPressed := ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight)
+    if rightPressed && mousePressed[ebiten.MouseButtonRight] {
+        viewCamera.Pan(screen2WorldLoc(dragScreenLoc).Sub(screen2WorldLoc(screenLoc)))
     }
-    towers = append(towers, newTower)
-}
+    mousePressed[ebiten.MouseButtonRight] = rightPressed
+    dragScreenLoc = screenLoc
 
-func createProjectile(source *Tower, target *Enemy) {
-    newProjectile := &Projectile {
-        position: source.position,
-        scale: source.scale,
-        target: target,
-        damage: 1,
+    _, wheelY := ebiten.Wheel()
+    if wheelY != 0.0 {
+        viewCamera.Zoom(math.Pow(cameraWheelZoom, -wheelY), screen2WorldLoc(screenLoc))
     }
-    projectiles = append(projectiles, newProjectile)
+    if keyJustPressed(ebiten.KeyF) {
+        viewCamera.Fit()
+    }
+    viewCamera.Update(game.camera, frameTime)
 }
 
+// NOTE: Frames that take longer than this (e.g. when the window is being dragged around) only advance
+//       the game by this much, rather than running a huge number of ticks to catch up all at once
+const maxFrameTime = 0.25
 
-func resizeCameraToContainRect(r Rect) {
-    minSize := pathBoundingBox.size.Add(Vec2{ 50, 50 })
-    xScaleFactor := minSize.x/camera.size.x
-    yScaleFactor := minSize.y/camera.size.y
-    scaleFactor := math.Max(xScaleFactor, yScaleFactor)
-    if currentWave > 0 {
-        // NOTE: We only scale after the first round so that we can just set the size to be what we
-        //       want it to look like at the beginning, and then it'll scale from there
-        ghostTower.scale *= scaleFactor
-    }
+// NOTE: F1 pauses, and F2 to F4 run the game at each of the other speeds
+var gameSpeedKeys = [...]ebiten.Key { ebiten.KeyF1, ebiten.KeyF2, ebiten.KeyF3, ebiten.KeyF4 }
+var gameSpeeds = [...]float64 { 0.0, 1.0, 2.0, 4.0 }
 
-    camera.position = pathBoundingBox.position
-    camera.size = camera.size.Mul(scaleFactor)
+func updateGameSpeed() {
+    for index,key := range gameSpeedKeys {
+        if keyJustPressed(key) {
+            gameSpeedIndex = index
+        }
+    }
 }
 
-func addPathSegment() {
-    pathEndLocation = pathEndLocation.Add(pathEndDirection.Mul(pathSegmentLength))
-    waypoints = append(waypoints, pathEndLocation)
-
-    // NOTE: Bit hackery to check the turn direction, from https://rosettacode.org/wiki/Dragon_curve
-    turnLowMask := pathEndIndex^(pathEndIndex-1)
-    turnCheckBit := pathEndIndex & (turnLowMask+1)
-    shouldTurnCCW := (turnCheckBit != 0)
-    if(!shouldTurnCCW) {
-        pathEndDirection = pathEndDirection.Rotate90CCW()
+func toggleMute() {
+    gameAudio.ToggleMute()
+    if gameAudio.Muted() {
+        showStatus("Sound muted (M to unmute)")
     } else {
-        pathEndDirection = pathEndDirection.Rotate90CW()
+        showStatus("Sound unmuted")
     }
-    pathEndIndex++
-
-    if !pathBoundingBox.ContainsPoint(pathEndLocation) {
-        minX := math.Min(pathEndLocation.x, pathBoundingBox.MinX())
-        maxX := math.Max(pathEndLocation.x, pathBoundingBox.MaxX())
-        minY := math.Min(pathEndLocation.y, pathBoundingBox.MinY())
-        maxY := math.Max(pathEndLocation.y, pathBoundingBox.MaxY())
+}
 
-        pathBoundingBox.position.x = (minX+maxX)/2.0
-        pathBoundingBox.position.y = (minY+maxY)/2.0
-        pathBoundingBox.size.x = maxX-minX
-        pathBoundingBox.size.y = maxY-minY
+func saveRecording() {
+    if (recorder == nil) || (recordPath == "") {
+        return
+    }
+    if err := recorder.Save(recordPath); err != nil {
+        log.Printf("Failed to save the replay to %s: %v", recordPath, err)
+    }
+}
 
-        resizeCameraToContainRect(pathBoundingBox)
+// recordHighScore adds the game that was just lost to its high score table, and saves the tables.
+func recordHighScore() {
+    highScoreRank = highScores.Add(&game.params, game.HighScore(time.Now()))
+    if err := highScores.WriteFile(highScoresPath); err != nil {
+        log.Printf("Failed to save the high scores to %s: %v", highScoresPath, err)
+        showStatus("Failed to save the high scores")
     }
 }
 
-func update(screen *ebiten.Image) error {
-    if ebiten.IsKeyPressed(ebiten.KeyEscape) {
-        os.Exit(0)
+// saveDailyResult writes out the daily challenge game that was just lost, so that it can be verified,
+// as long as it beat the best one so far.
+func saveDailyResult() {
+    written, err := dailyRecorder.Result(game).WriteFileIfBetter(dailyResultPath)
+    if err != nil {
+        log.Printf("Failed to save the daily challenge result to %s: %v", dailyResultPath, err)
+        showStatus("Failed to save the daily challenge result")
+        return
     }
+    if written {
+        showStatus("Saved your result to " + dailyResultPath)
+    } else {
+        showStatus("Your best result is still the one in " + dailyResultPath)
+    }
+}
 
-    screen.Fill(color.Black)
+func showStatus(msg string) {
+    statusMsg = msg
+    statusMsgTimeRemaining = 3.0
+}
 
-    sPressed := ebiten.IsKeyPressed(ebiten.KeyS)
-    if sPressed && !keyWasDown[ebiten.KeyS] {
-        if (len(enemies) == 0) && (waveEnemiesRemaining == 0) && (lives > 0) && waypointsReady {
-            startRound()
-        }
+func saveGame() {
+    if game.params.Daily != "" {
+        // NOTE: A game that was saved and loaded again couldn't be verified from its replay
+        showStatus("There's no saving in the daily challenge")
+        return
     }
-    keyWasDown[ebiten.KeyS] = sPressed
-
-    gPressed := ebiten.IsKeyPressed(ebiten.KeyG)
-    if gPressed && !keyWasDown[ebiten.KeyG] {
-        ghostTowerVisible = !ghostTowerVisible
+    if !game.canSave() {
+        showStatus("You can only save in between waves")
+        return
     }
-    keyWasDown[ebiten.KeyG] = gPressed
+    if err := game.Save().WriteFile(savePath); err != nil {
+        log.Printf("Failed to save the game to %s: %v", savePath, err)
+        showStatus("Failed to save the game")
+        return
+    }
+    showStatus("Saved the game to " + savePath)
+}
 
-    rPressed := ebiten.IsKeyPressed(ebiten.KeyR)
-    if rPressed && (lives == 0) {
-        reset()
+func loadGame(path string) error {
+    save, err := loadSaveGame(path)
+    if err != nil {
+        return err
     }
+    game.Load(save)
+    if recorder != nil {
+        // NOTE: Loading throws away everything that came before it, so the replay starts over too
+        recorder = newReplayRecorder(save.Params, save)
+    }
+    return nil
+}
 
-    mouseX, mouseY := ebiten.CursorPosition()
-    mouseScreenLoc := Vec2 { float64(mouseX), float64(mouseY) }
-    mouseWorldLoc := screen2WorldLoc(mouseScreenLoc)
+func towerTypeColor(towerType *TowerType) ebiten.ColorM {
+    return ebiten.ScaleColor(towerType.tint[0], towerType.tint[1], towerType.tint[2], 1.0)
+}
+
+func update(screen *ebiten.Image) error {
+    screen.Fill(color.Black)
 
-    ghostTower.position = mouseWorldLoc
+    now := time.Now()
+    frameGap := now.Sub(lastFrameTime).Seconds()
+    frameTime := math.Min(frameGap, maxFrameTime)
+    lastFrameTime = now
 
-    leftPressed := ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
-    if leftPressed && !mousePressed[ebiten.MouseButtonLeft] {
-        if ghostTowerVisible {
-            if (credits >= ghostTower.cost) && waypointsReady {
-                addTower(ghostTower.position)
-                credits -= ghostTower.cost
-            }
-        } else {
-            ghostTowerVisible = true
+    menuOpen := updateMenu(frameGap > focusLossGap)
+    var frameInput Input
+    if menuOpen {
+        frameInput.cursorLoc = screen2WorldLoc(cursorScreenLoc())
+    } else {
+        updateViewCamera(frameTime)
+        updateGameSpeed()
+        if keyJustPressed(ebiten.KeyM) {
+            toggleMute()
         }
+        frameInput = pollInput()
     }
-    mousePressed[ebiten.MouseButtonLeft] = leftPressed
-
-    if waveEnemiesRemaining > 0 {
-        timeTillEnemySpawn -= deltaTime
-        for (timeTillEnemySpawn < 0.0) && (waveEnemiesRemaining > 0) {
-            timeTillEnemySpawn += enemySpawnInterval
-            waveEnemiesRemaining--
-            sendEnemy()
-        }
+    cursorOverUI := hud.Update(&frameInput, menuOpen)
+    if menuOpen || (gameSpeeds[gameSpeedIndex] == 0.0) {
+        // NOTE: Nothing happens while paused, so don't let presses pile up to all happen on unpause
+        pendingInput = Input { cursorLoc: frameInput.cursorLoc }
+    } else {
+        pendingInput = pendingInput.Merge(frameInput)
+        tickAccumulator += frameTime*gameSpeeds[gameSpeedIndex]
     }
-
-
-    if !waypointsReady && (len(waypoints) < targetWaypointCount) {
-        timeTillNewWaypoint -= deltaTime
-        for timeTillNewWaypoint <= 0.0 {
-            addPathSegment()
-            timeTillNewWaypoint += waypointSpawnInterval
+    replaying := (replayPlayer != nil) && !replayPlayer.Finished()
+    if !replaying && !menuOpen {
+        if keyJustPressed(ebiten.KeyF5) {
+            saveGame()
         }
-        if len(waypoints) == targetWaypointCount {
-            waypointsReady = true
+        if keyJustPressed(ebiten.KeyF9) {
+            if game.params.Daily != "" {
+                showStatus("There's no loading in the daily challenge")
+            } else if err := loadGame(savePath); err != nil {
+                log.Printf("Failed to load the game from %s: %v", savePath, err)
+                showStatus("Failed to load the game")
+            } else {
+                showStatus("Loaded the game from " + savePath)
+            }
         }
     }
-
-    for index,enemy := range enemies {
-        if enemy == nil {
-            continue
+    for tickAccumulator >= deltaTime {
+        tickAccumulator -= deltaTime
+        input := pendingInput
+        // NOTE: One-off presses only go to the first tick of the frame
+        pendingInput = Input { cursorLoc: frameInput.cursorLoc }
+        tickReplayed := (replayPlayer != nil) && !replayPlayer.Finished()
+        if tickReplayed {
+            // NOTE: We still poll above so that our key/mouse edge-detection stays in sync for when
+            //       the replay finishes and control is handed back to the player
+            input = replayPlayer.Next()
         }
-        enemy.Update()
-        if enemy.health <= 0 {
-            credits += enemyBounty
-            enemies[index] = enemies[len(enemies)-1]
-            enemies[len(enemies)-1] = nil
-            enemies = enemies[:len(enemies)-1]
-            continue
+        if recorder != nil {
+            recorder.Record(input)
         }
-        if enemy.currentWaypoint == len(waypoints) {
-            lives--
-            if lives == 0 {
-                waveInProgress = false
-                blackoutOpacity = 0.0
-            } else if lives < 0 {
-                lives = 0
-            }
-            enemy.health = -1
-            enemies[index] = enemies[len(enemies)-1]
-            enemies[len(enemies)-1] = nil
-            enemies = enemies[:len(enemies)-1]
-
-            continue
+        if dailyRecorder != nil {
+            dailyRecorder.Record(game, input)
         }
-    }
-    for _,tower := range towers {
-        tower.Update()
-
-        if tower.currentTarget != nil {
-            targetOffset := tower.currentTarget.position.Sub(tower.position)
-            if targetOffset.Magnitude() > towerAttackRange*tower.scale {
-                tower.currentTarget = nil
-            }
+        displacedTowerCount := game.displacedTowerCount
+        livesBefore := game.lives
+        game.Step(input)
+        gameAudio.HandleEvents(game.events)
+        if game.displacedTowerCount > displacedTowerCount {
+            showStatus("The path grew over a tower, so it was refunded")
         }
-        if (tower.currentTarget == nil) {
-            for _,enemy := range enemies {
-                offset := enemy.position.Sub(tower.position)
-                if offset.Magnitude() < towerAttackRange*tower.scale {
-                    tower.currentTarget = enemy
-                    break
+        if (livesBefore > 0) && (game.lives == 0) {
+            // NOTE: Watching someone else's game doesn't get you onto the high score table
+            highScoreRank = -1
+            if !tickReplayed {
+                recordHighScore()
+                if game.params.Daily != "" {
+                    saveDailyResult()
                 }
             }
         }
     }
-    for index,projectile := range projectiles {
-        if projectile == nil {
-            continue // NOTE: I mean this works, but is there a less-hacky way of removing inside a range?
-        }
-        projectile.Update()
-        if projectile.isDead {
-            projectiles[index] = projectiles[len(projectiles)-1]
-            projectiles[len(projectiles)-1] = nil
-            projectiles = projectiles[:len(projectiles)-1]
-            continue
-        }
-    }
-
-    if waveInProgress && (len(enemies) == 0) && (waveEnemiesRemaining == 0) && (lives > 0) {
-        endRound()
-    }
+    gameAudio.Update()
+    statusMsgTimeRemaining -= frameTime
 
     if ebiten.IsRunningSlowly() {
         return nil
     }
 
+    // NOTE: How far we are between the last tick and the next one, for interpolating positions
+    tickFraction := tickAccumulator/deltaTime
+    mouseWorldLoc := frameInput.cursorLoc
+    if replaying {
+        mouseWorldLoc = game.ghostTower.position
+    }
+    waypoints := game.waypoints
+    ghostTower := game.ghostTower
+
     bgOpts := ebiten.DrawImageOptions{}
     screen.DrawImage(backgroundImg, &bgOpts)
-    pathSize := 10.0
     white := ebiten.ColorM{}
 
     for i := 1; i<len(waypoints); i++ {
         prevWaypoint := waypoints[i-1]
         nextWaypoint := waypoints[i]
 
-        drawLine(screen, prevWaypoint, nextWaypoint, pathSize, white)
+        drawLine(screen, prevWaypoint, nextWaypoint, game.params.PathWidth, white)
     }
 
     pathStartDir := waypoints[1].Sub(waypoints[0])
     pathStartAngle := math.Atan2(pathStartDir.y, pathStartDir.x)
     pathEndDir := waypoints[len(waypoints)-1].Sub(waypoints[len(waypoints)-2])
     pathEndAngle := math.Atan2(pathEndDir.y, pathEndDir.x)
-    drawSprite(screen, waypoints[0], pathSize, pathStartAngle, pathStartImg, white)
-    drawSprite(screen, waypoints[len(waypoints)-1], pathSize, pathEndAngle, pathEndImg, white)
+    drawSprite(screen, waypoints[0], game.params.PathWidth, pathStartAngle, pathStartImg, white)
+    drawSprite(screen, waypoints[len(waypoints)-1], game.params.PathWidth, pathEndAngle, pathEndImg, white)
 
-    for _,enemy := range enemies {
-        drawSprite(screen, enemy.position, enemySize, 0, enemyImg[enemy.animFrame], white)
+    for _,enemy := range game.enemies {
+        enemyType := enemy.Type()
+        enemyClr := ebiten.ScaleColor(enemyType.tint[0], enemyType.tint[1], enemyType.tint[2], 1.0)
+        effectTint := enemy.EffectTint()
+        enemyClr.Concat(ebiten.ScaleColor(effectTint[0], effectTint[1], effectTint[2], 1.0))
+        drawSprite(screen, enemy.prevPosition.Lerp(enemy.position, tickFraction), enemy.size, 0, enemyImg[enemyType.frames[enemy.animFrame]], enemyClr)
     }
     rangeClr := ebiten.ScaleColor(1,1,1,0.3)
-    for _,tower := range towers {
-        if mouseWorldLoc.Sub(tower.position).Magnitude() < 12.0 {
-            drawCircle(screen, tower.position, towerAttackRange*tower.scale, rangeClr)
+    hoveredTower := game.towerAt(mouseWorldLoc)
+    for _,tower := range game.towers {
+        if (tower == hoveredTower) || (tower == game.selectedTower) {
+            drawCircle(screen, tower.position, tower.Range(), rangeClr)
         }
-        drawSprite(screen, tower.position, tower.scale*towerSize, 0, towerImg[tower.animFrame], white)
+        drawSprite(screen, tower.position, tower.scale*game.params.TowerSize, 0, towerImg[tower.animFrame], towerTypeColor(tower.Type()))
     }
-    for _,proj := range projectiles {
-        drawSprite(screen, proj.position, projectileSize*proj.scale, proj.rotation, projectileImg, white)
+    for _,proj := range game.projectiles {
+        size := proj.size
+        if proj.critical {
+            size *= 1.5
+        }
+        drawSprite(screen, proj.prevPosition.Lerp(proj.position, tickFraction), size, proj.rotation, projectileImg, towerTypeColor(proj.Type()))
     }
 
-    ghostTowerClr := white
+    ghostTowerClr := towerTypeColor(ghostTower.Type())
     ghostTowerClr.Scale(1,1,1,0.5)
     ghostRangeClr := rangeClr
     ghostRangeClr.Scale(1,1,1,0.5)
-    if ghostTowerVisible {
-        if credits >= ghostTower.cost {
-            drawSprite(screen, ghostTower.position, ghostTower.scale*towerSize, 0, towerCanBuildImg, ghostTowerClr)
-        } else {
-            drawSprite(screen, ghostTower.position, ghostTower.scale*towerSize, 0, towerNoCanBuildImg, ghostTowerClr)
+    placement := game.checkPlacement(mouseWorldLoc)
+    if game.ghostTowerVisible && !cursorOverUI {
+        switch placement {
+        case placementOK:
+            drawSprite(screen, mouseWorldLoc, ghostTower.scale*game.params.TowerSize, 0, towerCanBuildImg, ghostTowerClr)
+        case placementOnPath, placementOnTower:
+            blockedClr := ebiten.ScaleColor(1.0, 0.4, 0.4, 0.5)
+            drawSprite(screen, mouseWorldLoc, ghostTower.scale*game.params.TowerSize, 0, towerNoCanBuildImg, blockedClr)
+        default:
+            drawSprite(screen, mouseWorldLoc, ghostTower.scale*game.params.TowerSize, 0, towerNoCanBuildImg, ghostTowerClr)
         }
-        drawCircle(screen, ghostTower.position, towerAttackRange*ghostTower.scale, ghostRangeClr)
+        drawCircle(screen, mouseWorldLoc, ghostTower.Range(), ghostRangeClr)
     }
 
-    if (lives == 0) {
-        blackoutOpacity = math.Min(1.0, blackoutOpacity + 1.0*deltaTime)
+    if (game.lives == 0) {
+        blackoutOpacity = math.Min(1.0, blackoutOpacity + 1.0*frameTime)
         blackoutClr := ebiten.ScaleColor(0,0,0,blackoutOpacity)
 
         opts := ebiten.DrawImageOptions{}
         opts.GeoM.Scale(screenWidth, screenHeight)
         opts.ColorM = blackoutClr
         screen.DrawImage(pixelImg, &opts)
-    }
-
-    var msg string
-    if (lives == 0) {
-        msg = fmt.Sprintf("You lost on wave %d :(\nPress R to restart", currentWave)
-
-    } else if (len(enemies) == 0) && (waveEnemiesRemaining == 0) {
-        if currentWave == 0 {
-            msg = fmt.Sprintf(
-                "Lives: %d\n" +
-                "Credits: %d\n" +
-                "Tower cost: %d\n" +
-                "Press S to start the wave %d\n" +
-                "Press Left mouse to place a tower (will show the cursor instead, if its hidden)\n" +
-                "Mouse-over an existing tower to see its attack range\n" +
-                "Press G to toggle the place-tower cursor\n" +
-                "Press Esc to quit at any time",
-                lives, credits, ghostTower.cost, currentWave+1)
-        } else {
-            msg = fmt.Sprintf(
-                "Lives: %d\n" +
-                "Credits: %d\n" +
-                "Tower cost: %d\n" +
-                "Press S to start the wave %d",
-                lives, credits, ghostTower.cost, currentWave+1)
-        }
-
     } else {
-        msg = fmt.Sprintf(
-            "Lives: %d\n" +
-            "Credits: %d\n" +
-            "Tower cost: %d",
-            lives, credits, ghostTower.cost)
+        blackoutOpacity = 0.0
     }
-    ebitenutil.DebugPrint(screen, msg)
-    return nil
-}
 
-func reset() {
-    enemies = enemies[:0]
-    towers = towers[:0]
-    projectiles = projectiles[:0]
-
-    projectileSpeed = 300.0
-    enemySpeed = 15.0
-    enemiesPerWave = 0
-    enemyBounty = 1
-    enemyHealth = 1
-    currentWave = 0
-    lives = 10
-
-    cameraWidth := float64(screenWidth)
-    cameraHeight := float64(screenHeight)
-    camera = Rect {
-        position: Vec2 { cameraWidth/2.0, cameraHeight/2.0 },
-        size: Vec2 { float64(cameraWidth), float64(cameraHeight) },
-    }
-
-    credits = 2
-
-    ghostTowerVisible = true
-    ghostTower.scale = 1.0
-    ghostTower.attackRange = 25.0
-    ghostTower.cost = 2
-
-    pathBoundingBox = Rect {}
-    pathEndDirection = Vec2 { -1.0, 0.0 }
-    pathEndLocation = Vec2 { 0.0, 0.0 }
-    pathEndIndex = 1
-    waypoints = waypoints[:1]
-    waypoints[0] = pathEndLocation
-    for i:=1; i<8; i++ {
-        addPathSegment()
-    }
-    waypointsReady = true
+    showPlacement := game.ghostTowerVisible && (game.selectedTower == nil) && !cursorOverUI
+    hud.refresh(placement, showPlacement, menuOpen)
+    hud.Draw(screen, menuOpen)
+    return nil
 }
 
 func loadImage(path string) *ebiten.Image {
@@ -487,6 +461,17 @@ func loadImage(path string) *ebiten.Image {
 }
 
 func main() {
+    replayPath := flag.String("replay", "", "Play back the replay file at the given path")
+    flag.StringVar(&recordPath, "record", "", "Record all input to a replay file at the given path")
+    flag.StringVar(&savePath, "save", "idoad-save.json", "The file that F5 saves to and F9 loads from")
+    loadPath := flag.String("load", "", "Resume the saved game at the given path")
+    flag.StringVar(&dailyResultPath, "daily-result", "", "Where to write the daily challenge's result (idoad-daily-<date>.json by default)")
+    flag.StringVar(&highScoresPath, "scores", "idoad-scores.json", "The file that the high scores are kept in")
+    mute := flag.Bool("mute", false, "Start with the sound muted")
+    flag.BoolVar(&pauseOnFocusLoss, "pause-on-focus-loss", false, "Pause whenever the game goes half a second without a frame, which usually means the window lost focus")
+    paramFlags := addParamFlags()
+    flag.Parse()
+
     circleImg = loadImage("_resources/circle.png")
     backgroundImg = loadImage("_resources/background.png")
     towerImg[0] = loadImage("_resources/tower_1.png")
@@ -508,17 +493,73 @@ func main() {
 
     pixelImg,_ = ebiten.NewImage(1,1, ebiten.FilterNearest)
     pixelImg.Fill(color.White)
+    font, err := loadFont("_resources/font.png")
+    if err != nil {
+        log.Fatal(err)
+    }
+    hud = newHUD(font)
+    highScores, err = loadHighScores(highScoresPath)
+    if err != nil {
+        log.Fatal(err)
+    }
+    audioBackend, err := newEbitenAudioBackend()
+    if err != nil {
+        log.Printf("Failed to start the audio, continuing without sound: %v", err)
+        gameAudio = newMixer(nullAudioBackend {})
+    } else {
+        gameAudio = newMixer(audioBackend)
+    }
+    if *mute {
+        gameAudio.ToggleMute()
+    }
 
-    enemies = make([]*Enemy, 0)
-    towers = make([]*Tower, 0)
-    projectiles = make([]*Projectile, 0)
-    waypoints = make([]Vec2, 1)
-
-    ghostTower = &Tower{}
-
-    reset()
+    var replayStart *SaveGame
+    if *replayPath != "" {
+        replay, err := loadReplay(*replayPath)
+        if err != nil {
+            log.Fatal(err)
+        }
+        game = newGameStateFromReplay(replay)
+        replayPlayer = newReplayPlayer(replay)
+        replayStart = replay.Start
+    } else {
+        params, err := paramFlags.Params()
+        if err != nil {
+            log.Fatal(err)
+        }
+        if !paramFlags.SeedGiven() {
+            // NOTE: The headless builds stick with the default, so that running them again gives the same results
+            seedRNG := newRNG(time.Now().UnixNano())
+            params.Seed = seedRNG.PickSeed()
+        }
+        game = newGameState(params)
+    }
+    viewCamera = newViewCamera(game.camera)
+    if recordPath != "" {
+        recorder = newReplayRecorder(game.params, replayStart)
+    }
+    if game.params.Daily != "" {
+        dailyRecorder = newDailyRecorder(game.params)
+    }
+    if dailyResultPath == "" {
+        dailyResultPath = "idoad-daily-" + game.params.Daily + ".json"
+    }
+    if *loadPath != "" {
+        if replayPlayer != nil {
+            log.Fatal("Cannot load a saved game while playing back a replay")
+        }
+        if game.params.Daily != "" {
+            log.Fatal("Cannot load a saved game in the daily challenge")
+        }
+        if err := loadGame(*loadPath); err != nil {
+            log.Fatal(err)
+        }
+    }
 
-    if err := ebiten.Run(update, screenWidth, screenHeight, 2, "In Defence of a Dragon"); err != nil {
+    lastFrameTime = time.Now()
+    err = ebiten.Run(update, screenWidth, screenHeight, 2, "In Defence of a Dragon")
+    saveRecording()
+    if err != nil {
         log.Fatal(err)
     }
 }
@@ -0,0 +1,228 @@
+// +build !headless
+
+package main
+
+import (
+    "fmt"
+    "os"
+
+    "github.com/hajimehoshi/ebiten"
+)
+
+type MenuScreen int
+
+const (
+    menuNone MenuScreen = iota
+    menuPause
+    menuSettings
+    menuHighScores
+    menuConfirmQuit
+)
+
+// NOTE: The game doesn't get updated at all while the window is in the background, so a long enough
+//       gap in between frames usually means that it lost focus. The window can't tell us that
+//       directly, and an ordinary stall (loading, a slow machine) looks just the same, so pausing
+//       on a gap is only turned on by -pause-on-focus-loss or the settings menu.
+const focusLossGap = 0.5
+
+var (
+    currentMenu MenuScreen
+    menuSelection int
+    pauseOnFocusLoss = false
+    highScoreTableIndex int // NOTE: Which of the high score tables the high score menu is showing
+)
+
+var pauseMenuItems = [...]string { "Resume", "Save", "High scores", "Settings", "Quit" }
+
+// openPauseMenuAt goes back to the pause menu from one of its sub-menus, with that sub-menu selected.
+func openPauseMenuAt(item string) {
+    openMenu(menuPause)
+    for index,name := range pauseMenuItems {
+        if name == item {
+            menuSelection = index
+        }
+    }
+}
+
+// openHighScores opens the high score menu on the table for the current game, if it has one.
+func openHighScores() {
+    openMenu(menuHighScores)
+    highScoreTableIndex = 0
+    for index := range highScores.Tables {
+        if &highScores.Tables[index] == highScores.Table(&game.params) {
+            highScoreTableIndex = index
+        }
+    }
+}
+
+func openMenu(screen MenuScreen) {
+    currentMenu = screen
+    menuSelection = 0
+}
+
+func quitGame() {
+    saveRecording()
+    os.Exit(0)
+}
+
+// moveMenuSelection moves the selection up or down with the arrow keys (or W/S), wrapping around.
+func moveMenuSelection(itemCount int) {
+    up := anyKeyJustPressed(ebiten.KeyUp, ebiten.KeyW)
+    down := anyKeyJustPressed(ebiten.KeyDown, ebiten.KeyS)
+    if up {
+        menuSelection = (menuSelection + itemCount - 1) % itemCount
+    }
+    if down {
+        menuSelection = (menuSelection + 1) % itemCount
+    }
+}
+
+func menuConfirmPressed() bool {
+    return anyKeyJustPressed(ebiten.KeyEnter, ebiten.KeySpace)
+}
+
+// updateMenu handles the input for whichever menu is open (or opens one), and reports whether a menu
+// is open, in which case the game should be paused and the normal controls ignored.
+func updateMenu(focusLost bool) bool {
+    escape := keyJustPressed(ebiten.KeyEscape)
+    pause := keyJustPressed(ebiten.KeyP)
+
+    switch currentMenu {
+    case menuNone:
+        if escape {
+            openMenu(menuConfirmQuit)
+        } else if pause || (focusLost && pauseOnFocusLoss) {
+            openMenu(menuPause)
+        }
+
+    case menuPause:
+        moveMenuSelection(len(pauseMenuItems))
+        if escape || pause {
+            openMenu(menuNone)
+        } else if menuConfirmPressed() {
+            switch pauseMenuItems[menuSelection] {
+            case "Resume":
+                openMenu(menuNone)
+            case "Save":
+                saveGame()
+            case "High scores":
+                openHighScores()
+            case "Settings":
+                openMenu(menuSettings)
+            case "Quit":
+                openMenu(menuConfirmQuit)
+            }
+        }
+
+    case menuSettings:
+        items := settingsMenuItems()
+        moveMenuSelection(len(items))
+        if escape {
+            openPauseMenuAt("Settings")
+        } else if menuConfirmPressed() {
+            switch menuSelection {
+            case 0:
+                // NOTE: Skip over paused, the pause menu is the way to pause
+                gameSpeedIndex = 1 + gameSpeedIndex%(len(gameSpeeds)-1)
+            case 1:
+                pauseOnFocusLoss = !pauseOnFocusLoss
+            case 2, 3, 4:
+                cycleVolume(AudioBus(menuSelection-2))
+            case 5:
+                gameAudio.ToggleMute()
+            case 6:
+                openPauseMenuAt("Settings")
+            }
+        }
+
+    case menuHighScores:
+        tableCount := len(highScores.Tables)
+        previous := anyKeyJustPressed(ebiten.KeyLeft, ebiten.KeyA)
+        next := anyKeyJustPressed(ebiten.KeyRight, ebiten.KeyD)
+        if previous && (tableCount > 0) {
+            highScoreTableIndex = (highScoreTableIndex + tableCount - 1) % tableCount
+        }
+        if next && (tableCount > 0) {
+            highScoreTableIndex = (highScoreTableIndex + 1) % tableCount
+        }
+        if escape || menuConfirmPressed() {
+            openPauseMenuAt("High scores")
+        }
+
+    case menuConfirmQuit:
+        yes := anyKeyJustPressed(ebiten.KeyY, ebiten.KeyEnter)
+        no := keyJustPressed(ebiten.KeyN)
+        if yes {
+            quitGame()
+        } else if no || escape {
+            openMenu(menuNone)
+        }
+    }
+    return currentMenu != menuNone
+}
+
+// NOTE: Each press steps the volume up by this much, wrapping back around to silent after full volume
+const volumeStep = 0.2
+
+func cycleVolume(bus AudioBus) {
+    volume := gameAudio.Volume(bus) + volumeStep
+    if volume > 1.0 + 0.5*volumeStep {
+        volume = 0.0
+    }
+    gameAudio.SetVolume(bus, volume)
+}
+
+func onOff(value bool) string {
+    if value {
+        return "on"
+    }
+    return "off"
+}
+
+func settingsMenuItems() []string {
+    items := []string {
+        fmt.Sprintf("Game speed: %gx", gameSpeeds[gameSpeedIndex]),
+        "Pause when unfocused: " + onOff(pauseOnFocusLoss),
+    }
+    for bus := AudioBus(0); bus < busCount; bus++ {
+        items = append(items, fmt.Sprintf("%s volume: %.0f%%", bus, 100.0*gameAudio.Volume(bus)))
+    }
+    items = append(items, "Mute: " + onOff(gameAudio.Muted()))
+    return append(items, "Back")
+}
+
+func menuText() string {
+    var items []string
+    title := ""
+    switch currentMenu {
+    case menuPause:
+        title = fmt.Sprintf("Paused (seed %d)", game.params.Seed)
+        items = pauseMenuItems[:]
+    case menuSettings:
+        title = "Settings"
+        items = settingsMenuItems()
+    case menuHighScores:
+        if len(highScores.Tables) == 0 {
+            return "High scores\n\nNo games have been lost yet.\n\nEsc to go back"
+        }
+        table := &highScores.Tables[highScoreTableIndex]
+        return "High scores: " + table.Label() + "\n\n" +
+               table.Text(highScoreTableSize, -1) + "\n\n" +
+               "Left/Right: other tables, Esc: back"
+    case menuConfirmQuit:
+        return "Really quit?\n\n" +
+               "Anything since your last save will be\n" +
+               "lost.\n\n" +
+               "Press Y to quit, or N to carry on."
+    }
+
+    result := title + "\n\n"
+    for index,item := range items {
+        if index == menuSelection {
+            result += "> " + item + "\n"
+        } else {
+            result += "  " + item + "\n"
+        }
+    }
+    return result + "\nUp/Down to choose, Enter to select,\nEsc to go back"
+}
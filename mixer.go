@@ -0,0 +1,171 @@
+package main
+
+import (
+    "log"
+    "math"
+)
+
+// AudioBackend is whatever actually makes the noise. The mixer works out what should be playing and
+// how loudly, so that the backend just has to play it.
+type AudioBackend interface {
+    PlaySound(sound SoundID, volume float64)
+    SetMusicVolume(volume float64)
+    Update() error
+}
+
+// nullAudioBackend plays nothing, for when there's no sound device (or no display, in headless builds).
+type nullAudioBackend struct {}
+
+func (nullAudioBackend) PlaySound(sound SoundID, volume float64) {}
+func (nullAudioBackend) SetMusicVolume(volume float64) {}
+func (nullAudioBackend) Update() error { return nil }
+
+// Voice is a single copy of a sound that a backend can play, the way that ebiten's audio players work.
+type Voice interface {
+    IsPlaying() bool
+    Rewind() error
+    SetVolume(volume float64)
+    Play() error
+}
+
+// voicePool plays one sound on whichever of its voices is free, so that the same sound can overlap
+// itself a few times.
+// NOTE: Once they're all busy, the voice that was started the longest ago gets cut off and reused,
+//       since the newest sound is the one that goes with what's happening on screen
+type voicePool struct {
+    voices []Voice
+    started []int // NOTE: When each voice was last started, counted in plays
+    plays int
+}
+
+func newVoicePool(voices []Voice) *voicePool {
+    return &voicePool {
+        voices: voices,
+        started: make([]int, len(voices)),
+    }
+}
+
+func (p *voicePool) Play(volume float64) error {
+    if len(p.voices) == 0 {
+        return nil
+    }
+    chosen := 0
+    for index,voice := range p.voices {
+        if !voice.IsPlaying() {
+            chosen = index
+            break
+        }
+        if p.started[index] < p.started[chosen] {
+            chosen = index
+        }
+    }
+    p.plays++
+    p.started[chosen] = p.plays
+
+    voice := p.voices[chosen]
+    if err := voice.Rewind(); err != nil {
+        return err
+    }
+    voice.SetVolume(volume)
+    return voice.Play()
+}
+
+type AudioBus int
+
+const (
+    busMaster AudioBus = iota // NOTE: Scales both of the others
+    busMusic
+    busEffects
+
+    busCount
+)
+
+var audioBusNames = [busCount]string { "Master", "Music", "Effects" }
+
+func (b AudioBus) String() string {
+    return audioBusNames[b]
+}
+
+// NOTE: The sound that each kind of game event makes, and how loud it is relative to the others
+var eventSounds = [eventKindCount]struct {
+    sound SoundID
+    volume float64
+} {
+    eventTowerFired: { soundTowerFire, 0.4 },
+    eventEnemyKilled: { soundEnemyDeath, 0.7 },
+    eventEnemyLeaked: { soundEnemyLeak, 1.0 },
+    eventWaveStarted: { soundWaveStart, 1.0 },
+    eventPathGrew: { soundPathGrow, 0.5 },
+}
+
+// Mixer turns game events into sounds, and keeps track of the volume of each bus.
+type Mixer struct {
+    backend AudioBackend
+    volumes [busCount]float64
+    muted bool
+
+    // NOTE: Each sound only gets played once per frame no matter how many times it was triggered,
+    //       so that a dozen towers firing on the same tick don't come out a dozen times as loud
+    pending [soundCount]float64
+}
+
+func newMixer(backend AudioBackend) *Mixer {
+    m := &Mixer {
+        backend: backend,
+        volumes: [busCount]float64 { 0.8, 0.6, 1.0 },
+    }
+    m.backend.SetMusicVolume(m.busVolume(busMusic))
+    return m
+}
+
+func (m *Mixer) busVolume(bus AudioBus) float64 {
+    if m.muted {
+        return 0.0
+    }
+    if bus == busMaster {
+        return m.volumes[busMaster]
+    }
+    return m.volumes[busMaster]*m.volumes[bus]
+}
+
+func (m *Mixer) Volume(bus AudioBus) float64 {
+    return m.volumes[bus]
+}
+
+func (m *Mixer) SetVolume(bus AudioBus, volume float64) {
+    m.volumes[bus] = math.Max(0.0, math.Min(1.0, volume))
+    m.backend.SetMusicVolume(m.busVolume(busMusic))
+}
+
+func (m *Mixer) Muted() bool {
+    return m.muted
+}
+
+func (m *Mixer) ToggleMute() {
+    m.muted = !m.muted
+    m.backend.SetMusicVolume(m.busVolume(busMusic))
+}
+
+// HandleEvents queues up the sounds for the given events, to be played on the next Update.
+func (m *Mixer) HandleEvents(events []GameEvent) {
+    for _,event := range events {
+        sound := eventSounds[event.kind]
+        m.pending[sound.sound] = math.Max(m.pending[sound.sound], sound.volume)
+    }
+}
+
+// Update plays everything that was queued up since the last update. It should be called once per frame.
+func (m *Mixer) Update() {
+    effectsVolume := m.busVolume(busEffects)
+    for sound,volume := range m.pending {
+        if (volume > 0.0) && (effectsVolume > 0.0) {
+            m.backend.PlaySound(SoundID(sound), volume*effectsVolume)
+        }
+        m.pending[sound] = 0.0
+    }
+    if err := m.backend.Update(); err != nil {
+        // NOTE: Losing the sound isn't worth stopping the game over, so just carry on without it
+        log.Printf("Audio failed, continuing without it: %v", err)
+        m.backend = nullAudioBackend {}
+    }
+}
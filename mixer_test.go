@@ -0,0 +1,175 @@
+package main
+
+import (
+    "errors"
+    "math"
+    "testing"
+)
+
+type playedSound struct {
+    sound SoundID
+    volume float64
+}
+
+// recordingAudioBackend remembers everything that it was asked to play, rather than playing it.
+type recordingAudioBackend struct {
+    played []playedSound
+    musicVolume float64
+    err error
+}
+
+func (b *recordingAudioBackend) PlaySound(sound SoundID, volume float64) {
+    b.played = append(b.played, playedSound { sound, volume })
+}
+
+func (b *recordingAudioBackend) SetMusicVolume(volume float64) {
+    b.musicVolume = volume
+}
+
+func (b *recordingAudioBackend) Update() error {
+    return b.err
+}
+
+func TestMixerPlaysEachSoundOncePerFrame(t *testing.T) {
+    backend := &recordingAudioBackend {}
+    m := newMixer(backend)
+    m.HandleEvents([]GameEvent {
+        { kind: eventTowerFired },
+        { kind: eventTowerFired },
+        { kind: eventEnemyKilled },
+        { kind: eventTowerFired },
+    })
+    m.Update()
+
+    effectsVolume := m.Volume(busMaster)*m.Volume(busEffects)
+    expected := []playedSound {
+        { soundTowerFire, eventSounds[eventTowerFired].volume*effectsVolume },
+        { soundEnemyDeath, eventSounds[eventEnemyKilled].volume*effectsVolume },
+    }
+    if len(backend.played) != len(expected) {
+        t.Fatalf("expected %v to be played, but got %v", expected, backend.played)
+    }
+    for index := range expected {
+        if (backend.played[index].sound != expected[index].sound) ||
+           (math.Abs(backend.played[index].volume - expected[index].volume) > 1e-9) {
+            t.Errorf("expected %v to be played, but got %v", expected, backend.played)
+        }
+    }
+
+    m.Update()
+    if len(backend.played) != len(expected) {
+        t.Errorf("sounds from the last frame were played again: %v", backend.played[len(expected):])
+    }
+}
+
+func TestMixerVolumes(t *testing.T) {
+    backend := &recordingAudioBackend {}
+    m := newMixer(backend)
+    m.SetVolume(busMaster, 0.5)
+    m.SetVolume(busMusic, 2.0)
+    if m.Volume(busMusic) != 1.0 {
+        t.Errorf("a volume of 2 should be clipped to 1, but got %g", m.Volume(busMusic))
+    }
+    if backend.musicVolume != 0.5 {
+        t.Errorf("expected the music to play at half volume, but got %g", backend.musicVolume)
+    }
+    m.SetVolume(busEffects, -1.0)
+    if m.Volume(busEffects) != 0.0 {
+        t.Errorf("a volume of -1 should be clipped to 0, but got %g", m.Volume(busEffects))
+    }
+    m.HandleEvents([]GameEvent { { kind: eventWaveStarted } })
+    m.Update()
+    if len(backend.played) != 0 {
+        t.Errorf("sounds were played with the effects turned all the way down: %v", backend.played)
+    }
+
+    m.SetVolume(busEffects, 1.0)
+    m.ToggleMute()
+    if backend.musicVolume != 0.0 {
+        t.Errorf("muting left the music playing at %g", backend.musicVolume)
+    }
+    m.HandleEvents([]GameEvent { { kind: eventWaveStarted } })
+    m.Update()
+    if len(backend.played) != 0 {
+        t.Errorf("sounds were played while muted: %v", backend.played)
+    }
+    m.ToggleMute()
+    if backend.musicVolume != 0.5 {
+        t.Errorf("unmuting should put the music back to half volume, but got %g", backend.musicVolume)
+    }
+}
+
+func TestMixerCarriesOnWhenTheBackendFails(t *testing.T) {
+    backend := &recordingAudioBackend { err: errors.New("no sound device") }
+    m := newMixer(backend)
+    m.Update()
+    m.HandleEvents([]GameEvent { { kind: eventPathGrew } })
+    m.Update()
+    if len(backend.played) != 0 {
+        t.Errorf("the mixer kept using a backend that failed: %v", backend.played)
+    }
+}
+
+// fakeVoice plays until it's told that it has finished.
+type fakeVoice struct {
+    playing bool
+    volume float64
+    plays int
+}
+
+func (v *fakeVoice) IsPlaying() bool { return v.playing }
+func (v *fakeVoice) Rewind() error { return nil }
+func (v *fakeVoice) SetVolume(volume float64) { v.volume = volume }
+func (v *fakeVoice) Play() error {
+    v.playing = true
+    v.plays++
+    return nil
+}
+
+func TestVoicePoolReusesTheOldestVoice(t *testing.T) {
+    fakes := []*fakeVoice { {}, {}, {} }
+    voices := make([]Voice, len(fakes))
+    for index := range fakes {
+        voices[index] = fakes[index]
+    }
+    pool := newVoicePool(voices)
+    for index := range fakes {
+        pool.Play(0.1*float64(index+1))
+    }
+    for index,voice := range fakes {
+        if voice.plays != 1 {
+            t.Fatalf("with free voices, voice %d was played %d times instead of once", index, voice.plays)
+        }
+    }
+
+    // NOTE: Every voice is busy, so the first one to have started gets cut off, and then the next
+    pool.Play(0.4)
+    pool.Play(0.5)
+    if (fakes[0].plays != 2) || (fakes[0].volume != 0.4) || (fakes[1].plays != 2) || (fakes[1].volume != 0.5) {
+        t.Errorf("the oldest voices weren't reused: %+v %+v", *fakes[0], *fakes[1])
+    }
+
+    // NOTE: Once one has finished, it gets used before cutting off any of the others
+    fakes[2].playing = false
+    pool.Play(0.6)
+    if (fakes[2].plays != 2) || (fakes[0].plays != 2) {
+        t.Errorf("a busy voice was cut off while another one was free: %+v %+v", *fakes[0], *fakes[2])
+    }
+    pool.Play(0.7)
+    if fakes[0].plays != 3 {
+        t.Errorf("expected the oldest voice to be reused, but got %+v %+v %+v", *fakes[0], *fakes[1], *fakes[2])
+    }
+}
+
+func TestLoudSoundsClipRatherThanWrap(t *testing.T) {
+    buffer := newPCMBuffer(0.1)
+    for count := 0; count < 4; count++ {
+        buffer.addTone(Tone { waveform: waveSquare, duration: 0.1, fromFrequency: 100.0, toFrequency: 100.0, volume: 0.9 })
+    }
+    pcm := buffer.PCM()
+    middle := 4*(len(buffer.samples)/2)
+    value := int16(uint16(pcm[middle]) | uint16(pcm[middle+1])<<8)
+    if (value != math.MaxInt16) && (value != -math.MaxInt16) {
+        t.Errorf("a square wave at 3.6 times full volume should be clipped to %d, but got %d", math.MaxInt16, value)
+    }
+}
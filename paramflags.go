@@ -0,0 +1,251 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "io/ioutil"
+    "os"
+    "strings"
+    "time"
+)
+
+// paramFlags are the command-line flags that pick the GameParams for a new game, which are shared
+// between the normal and headless builds. The params start out as the defaults, then the config
+// file (if there is one) gets applied, and then any of the flags below that were actually given.
+// The daily challenge is the exception, since it picks all of the params itself.
+type paramFlags struct {
+    configPath *string
+    dumpConfig *bool
+    daily *bool
+    dailyDate *string
+
+    wavesPath *string
+    pathGenerator *string
+    pathDirections *string
+    difficulty *string
+    modifiers *string
+    seed *int64
+    towers *string
+
+    ints map[string]*int
+    floats map[string]*float64
+
+    seedGiven bool // NOTE: Whether the seed was picked by -seed, the config file or the daily challenge
+}
+
+// tuningFlag is a flag that overrides a single number in the params, named after its key in the
+// config file (but in kebab-case, like the rest of the flags). Exactly one of the fields should be set.
+type tuningFlag struct {
+    name string
+    usage string
+    intParam func(p *GameParams) *int
+    floatParam func(p *GameParams) *float64
+}
+
+var tuningFlags = []tuningFlag {
+    { name: "lives", usage: "The number of lives to start with", intParam: func(p *GameParams) *int { return &p.Lives } },
+    { name: "credits", usage: "The number of credits to start with", intParam: func(p *GameParams) *int { return &p.Credits } },
+    { name: "enemy-speed", usage: "The starting speed of the enemies", floatParam: func(p *GameParams) *float64 { return &p.EnemySpeed } },
+    { name: "enemy-health", usage: "The starting health of the enemies", intParam: func(p *GameParams) *int { return &p.EnemyHealth } },
+    { name: "enemy-bounty", usage: "The starting number of credits for each kill", intParam: func(p *GameParams) *int { return &p.EnemyBounty } },
+    { name: "projectile-speed", usage: "The starting speed of the projectiles", floatParam: func(p *GameParams) *float64 { return &p.ProjectileSpeed } },
+    { name: "path-segment-length", usage: "The length of each segment of the path", floatParam: func(p *GameParams) *float64 { return &p.PathSegmentLength } },
+    { name: "path-width", usage: "The width of the path", floatParam: func(p *GameParams) *float64 { return &p.PathWidth } },
+    { name: "tower-size", usage: "The starting size of the towers", floatParam: func(p *GameParams) *float64 { return &p.TowerSize } },
+    { name: "tower-range-scale", usage: "Multiplies the range of every type of tower", floatParam: func(p *GameParams) *float64 { return &p.TowerRangeScale } },
+    { name: "tower-cost-scale", usage: "Multiplies the starting cost of every type of tower", floatParam: func(p *GameParams) *float64 { return &p.TowerCostScale } },
+    { name: "projectile-size", usage: "The size of the projectiles", floatParam: func(p *GameParams) *float64 { return &p.ProjectileSize } },
+    { name: "enemy-size", usage: "The size of the enemies", floatParam: func(p *GameParams) *float64 { return &p.EnemySize } },
+}
+
+func addParamFlags() *paramFlags {
+    defaults := defaultGameParams()
+    result := &paramFlags {
+        configPath: flag.String("config", "", "Load the game's params from the given JSON file, which the other flags then override"),
+        dumpConfig: flag.Bool("dump-config", false, "Print the params that the game would use as a config file (apart from the waves, which -waves picks), and exit"),
+        daily: flag.Bool("daily", false, "Play the daily challenge, whose seed, path, towers and modifiers all come from the date"),
+        dailyDate: flag.String("daily-date", "", "The date of the daily challenge to play as YYYY-MM-DD, instead of today"),
+
+        wavesPath: flag.String("waves", "", "Load the wave definitions from the given file instead of using the built-in ones"),
+        pathGenerator: flag.String("path", defaults.PathGenerator, "The shape of the path: " + strings.Join(pathGeneratorNames, ", ") + " (levy overlaps itself in places)"),
+        pathDirections: flag.String("path-dirs", "", "The compass directions (N, E, S or W) for the list path generator to follow"),
+        difficulty: flag.String("difficulty", defaults.Difficulty, "How hard the game is: " + strings.Join(difficultyNames(), ", ")),
+        modifiers: flag.String("modifiers", "", "A comma-separated list of extra rules to play with: " + strings.Join(modifierNames[:], ", ")),
+        seed: flag.Int64("seed", defaults.Seed, "The seed for everything random in the game (if it isn't given, the normal build picks a new one every time)"),
+        towers: flag.String("towers", "", "A comma-separated list of the only tower types that can be built: " + strings.Join(towerTypeNames(), ", ")),
+
+        ints: make(map[string]*int),
+        floats: make(map[string]*float64),
+    }
+    for _,tuning := range tuningFlags {
+        if tuning.intParam != nil {
+            result.ints[tuning.name] = flag.Int(tuning.name, *tuning.intParam(&defaults), tuning.usage)
+        } else {
+            result.floats[tuning.name] = flag.Float64(tuning.name, *tuning.floatParam(&defaults), tuning.usage)
+        }
+    }
+    return result
+}
+
+// loadConfig reads params from a JSON file, along with whether the file picks the seed. Anything that
+// the file leaves out keeps its default.
+func loadConfig(path string) (GameParams, bool, error) {
+    params := defaultGameParams()
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return params, false, err
+    }
+    decoder := json.NewDecoder(bytes.NewReader(data))
+    // NOTE: Otherwise a misspelled key would just silently do nothing
+    decoder.DisallowUnknownFields()
+    if err := decoder.Decode(&params); err != nil {
+        return params, false, fmt.Errorf("failed to parse config %s: %v", path, err)
+    }
+    // NOTE: A seed of 0 is as good as any other, so the only way to tell is to look for the key
+    var seed struct {
+        Seed *int64 `json:"seed"`
+    }
+    if err := json.Unmarshal(data, &seed); err != nil {
+        return params, false, fmt.Errorf("failed to parse config %s: %v", path, err)
+    }
+    return params, seed.Seed != nil, nil
+}
+
+// NOTE: The flags other than the tuning ones that change the params, which -daily can't be used with
+var paramFlagNames = []string { "config", "waves", "path", "path-dirs", "difficulty", "modifiers", "seed", "towers" }
+
+// splitList splits up a comma-separated list from the command line, ignoring any empty entries.
+func splitList(list string) []string {
+    var result []string
+    for _,entry := range strings.Split(list, ",") {
+        if entry = strings.TrimSpace(entry); entry != "" {
+            result = append(result, entry)
+        }
+    }
+    return result
+}
+
+// Params returns the params from the config file with the flags applied to them, once they've been
+// parsed. If -dump-config was given, it prints them and exits instead.
+func (f *paramFlags) Params() (GameParams, error) {
+    given := make(map[string]bool)
+    flag.Visit(func(set *flag.Flag) {
+        given[set.Name] = true
+    })
+    if *f.daily || given["daily-date"] {
+        return f.dailyParams(given)
+    }
+
+    params := defaultGameParams()
+    if *f.configPath != "" {
+        var err error
+        params, f.seedGiven, err = loadConfig(*f.configPath)
+        if err != nil {
+            return params, err
+        }
+    }
+    for _,tuning := range tuningFlags {
+        if !given[tuning.name] {
+            continue
+        }
+        if tuning.intParam != nil {
+            *tuning.intParam(&params) = *f.ints[tuning.name]
+        } else {
+            *tuning.floatParam(&params) = *f.floats[tuning.name]
+        }
+    }
+    if *f.wavesPath != "" {
+        waves, err := loadWaveSet(*f.wavesPath)
+        if err != nil {
+            return params, err
+        }
+        params.Waves = waves
+    }
+    if given["path"] {
+        params.PathGenerator = *f.pathGenerator
+    }
+    if given["path-dirs"] {
+        params.PathDirections = *f.pathDirections
+    }
+    if given["difficulty"] {
+        params.Difficulty = *f.difficulty
+    }
+    if given["seed"] {
+        params.Seed = *f.seed
+        f.seedGiven = true
+    }
+    if given["modifiers"] {
+        params.Modifiers = splitList(*f.modifiers)
+    }
+    if given["towers"] {
+        params.AllowedTowers = splitList(*f.towers)
+    }
+
+    if err := params.validate(); err != nil {
+        if *f.configPath != "" {
+            return params, fmt.Errorf("invalid config (from %s and the command line): %v", *f.configPath, err)
+        }
+        return params, fmt.Errorf("invalid config: %v", err)
+    }
+    return params, f.dumpParams(params)
+}
+
+// dailyParams returns the params for the daily challenge, which none of the other params can be changed for.
+func (f *paramFlags) dailyParams(given map[string]bool) (GameParams, error) {
+    names := paramFlagNames
+    for _,tuning := range tuningFlags {
+        names = append(names, tuning.name)
+    }
+    for _,name := range names {
+        if given[name] {
+            return GameParams {}, fmt.Errorf("the daily challenge picks its own params, so it can't be used with -%s", name)
+        }
+    }
+
+    date := *f.dailyDate
+    if date == "" {
+        date = dailyDate(time.Now())
+    }
+    params, err := dailyParams(date)
+    if err != nil {
+        return params, err
+    }
+    f.seedGiven = true
+    return params, f.dumpParams(params)
+}
+
+// SeedGiven reports whether the params that Params returned came with a seed of their own, rather
+// than just the default one.
+func (f *paramFlags) SeedGiven() bool {
+    return f.seedGiven
+}
+
+// dumpedConfig is what -dump-config prints. The waves are left out since there are hundreds of lines
+// of them, and they're better off in a file of their own for -waves. The seed is left out unless it
+// was given, so that loading the config back in doesn't stop the normal build from picking new ones.
+// NOTE: These take the place of the fields of the same name in the params, since they're shallower
+type dumpedConfig struct {
+    GameParams
+    Waves *WaveSet `json:"waves,omitempty"`
+    Seed *int64 `json:"seed,omitempty"`
+}
+
+// dumpParams prints the params and exits if -dump-config was given, and does nothing otherwise.
+func (f *paramFlags) dumpParams(params GameParams) error {
+    if !*f.dumpConfig {
+        return nil
+    }
+    config := dumpedConfig { GameParams: params }
+    if f.seedGiven {
+        config.Seed = &params.Seed
+    }
+    data, err := json.MarshalIndent(config, "", "    ")
+    if err != nil {
+        return err
+    }
+    os.Stdout.Write(append(data, '\n'))
+    os.Exit(0)
+    return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+    "io/ioutil"
+    "path/filepath"
+    "testing"
+)
+
+func TestConfigCanGiveASeedOfZero(t *testing.T) {
+    for _,test := range []struct {
+        config string
+        seed int64
+        seedGiven bool
+    } {
+        { `{ "lives": 3 }`, defaultGameParams().Seed, false },
+        { `{ "lives": 3, "seed": 0 }`, 0, true },
+        { `{ "seed": 12 }`, 12, true },
+    } {
+        path := filepath.Join(t.TempDir(), "config.json")
+        if err := ioutil.WriteFile(path, []byte(test.config), 0644); err != nil {
+            t.Fatal(err)
+        }
+        params, seedGiven, err := loadConfig(path)
+        if err != nil {
+            t.Fatalf("failed to load %s: %v", test.config, err)
+        }
+        if (params.Seed != test.seed) || (seedGiven != test.seedGiven) {
+            t.Errorf("loading %s gave seed %d (given: %v), but expected %d (given: %v)",
+                     test.config, params.Seed, seedGiven, test.seed, test.seedGiven)
+        }
+    }
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+    "bytes"
+    "fmt"
+    "math"
+    "strings"
+)
+
+// PathGenerator yields the direction of each successive segment of the path that the enemies follow.
+// NOTE: Generators must be deterministic given the GameParams and RNG that created them, since saved
+//       games rebuild them by just asking for as many segments as the saved path had.
+type PathGenerator interface {
+    NextDirection() Vec2
+}
+
+// NOTE: Unlike the others, the Levy C curve runs back over itself, so some of its segments overlap
+var pathGeneratorNames = []string { "dragon", "hilbert", "gosper", "levy", "random", "list" }
+
+func newPathGenerator(params *GameParams, rng RNG) (PathGenerator, error) {
+    startDirection := Vec2 { -1.0, 0.0 }
+    switch params.PathGenerator {
+    case "dragon":
+        return &dragonGenerator { direction: startDirection, index: 1 }, nil
+    case "hilbert":
+        return newLSystemGenerator("A", map[byte]string {
+            'A': "+BF-AFA-FB+",
+            'B': "-AF+BFB+FA-",
+        }, "F", 90, 2), nil
+    case "gosper":
+        return newLSystemGenerator("A", map[byte]string {
+            'A': "A-B--B+A++AA+B-",
+            'B': "+A-BB--B-A++A+B",
+        }, "AB", 60, 1), nil
+    case "levy":
+        return newLSystemGenerator("F", map[byte]string {
+            'F': "+F--F+",
+        }, "F", 45, 1), nil
+    case "random":
+        return newRandomWalkGenerator(rng), nil
+    case "list":
+        return newListGenerator(params.PathDirections)
+    }
+    return nil, fmt.Errorf("unknown path generator %q, expected one of: %s",
+                           params.PathGenerator, strings.Join(pathGeneratorNames, ", "))
+}
+
+// dragonGenerator produces the Heighway dragon curve.
+type dragonGenerator struct {
+    direction Vec2
+    index int
+}
+
+func (d *dragonGenerator) NextDirection() Vec2 {
+    result := d.direction
+
+    // NOTE: Bit hackery to check the turn direction, from https://rosettacode.org/wiki/Dragon_curve
+    turnLowMask := d.index^(d.index-1)
+    turnCheckBit := d.index & (turnLowMask+1)
+    shouldTurnCCW := (turnCheckBit != 0)
+    if(!shouldTurnCCW) {
+        d.direction = d.direction.Rotate90CCW()
+    } else {
+        d.direction = d.direction.Rotate90CW()
+    }
+    d.index++
+    return result
+}
+
+// lsystemGenerator produces a curve by expanding an L-system, where '+' and '-' turn by the given
+// angle (in degrees) and every one of the draw symbols is a segment.
+// NOTE: The path has to keep growing without the start of it changing, so we only look at the turn
+//       in between each pair of segments (which ignores the overall rotation of the curve) and only
+//       expand levelStep levels at a time. All of the curves we use have the property that their
+//       first segments then always turn the same way no matter how far they've been expanded.
+type lsystemGenerator struct {
+    axiom string
+    rules map[byte]string
+    drawSymbols string
+    angle int
+    levelStep int
+
+    level int
+    turns []int
+    heading int
+    index int
+}
+
+func newLSystemGenerator(axiom string, rules map[byte]string, drawSymbols string, angle, levelStep int) *lsystemGenerator {
+    return &lsystemGenerator {
+        axiom: axiom,
+        rules: rules,
+        drawSymbols: drawSymbols,
+        angle: angle,
+        levelStep: levelStep,
+        heading: 180,
+    }
+}
+
+func (l *lsystemGenerator) expand() {
+    l.level += l.levelStep
+    curve := l.axiom
+    for i := 0; i < l.level; i++ {
+        var next bytes.Buffer
+        for j := 0; j < len(curve); j++ {
+            if replacement,ok := l.rules[curve[j]]; ok {
+                next.WriteString(replacement)
+            } else {
+                next.WriteByte(curve[j])
+            }
+        }
+        curve = next.String()
+    }
+
+    l.turns = l.turns[:0]
+    turn := 0
+    for i := 0; i < len(curve); i++ {
+        switch {
+        case curve[i] == '+':
+            turn += l.angle
+        case curve[i] == '-':
+            turn -= l.angle
+        case strings.IndexByte(l.drawSymbols, curve[i]) >= 0:
+            l.turns = append(l.turns, turn)
+            turn = 0
+        }
+    }
+}
+
+func (l *lsystemGenerator) NextDirection() Vec2 {
+    for l.index >= len(l.turns) {
+        l.expand()
+    }
+    if l.index > 0 {
+        l.heading = (l.heading + l.turns[l.index] + 360) % 360
+    }
+    l.index++
+    return directionFromDegrees(l.heading)
+}
+
+// directionFromDegrees returns the unit vector pointing at the given angle.
+// NOTE: Right angles are special-cased so that axis-aligned paths stay exactly on their grid
+func directionFromDegrees(degrees int) Vec2 {
+    switch degrees {
+    case 0:
+        return Vec2 { 1.0, 0.0 }
+    case 90:
+        return Vec2 { 0.0, 1.0 }
+    case 180:
+        return Vec2 { -1.0, 0.0 }
+    case 270:
+        return Vec2 { 0.0, -1.0 }
+    }
+    radians := float64(degrees)*math.Pi/180.0
+    return Vec2 { math.Cos(radians), math.Sin(radians) }
+}
+
+type gridCell struct {
+    x int
+    y int
+}
+
+// randomWalkGenerator wanders around a grid at random, never going anywhere it has already been.
+// NOTE: The path can't be taken back once it's been handed out, so instead of backtracking when it
+//       gets stuck, the walk never steps into a pocket that it has walled off for itself. Only
+//       cells with a way out past the edge of everywhere it's been are allowed, and one of those
+//       always stays available because the open space outside goes on forever. Rather than
+//       searching for a way out from every cell on every step, the pockets get filled in as soon
+//       as they're closed off, so that they're walled off just like the path itself.
+type randomWalkGenerator struct {
+    rng RNG
+    cell gridCell
+    direction gridCell
+    visited map[gridCell]bool
+    enclosed map[gridCell]bool // NOTE: Cells in pockets that the walk has cut off from the outside
+    min gridCell // NOTE: The bounds of the visited cells, anything outside them is open space
+    max gridCell
+}
+
+var gridDirections = [...]gridCell { { -1, 0 }, { 0, -1 }, { 1, 0 }, { 0, 1 } }
+
+// NOTE: The 8 cells around a cell, in order so that each one is next to the one before it
+var gridRing = [...]gridCell { { 0, -1 }, { 1, -1 }, { 1, 0 }, { 1, 1 }, { 0, 1 }, { -1, 1 }, { -1, 0 }, { -1, -1 } }
+
+func newRandomWalkGenerator(rng RNG) *randomWalkGenerator {
+    result := &randomWalkGenerator {
+        rng: rng,
+        direction: gridDirections[0],
+        visited: make(map[gridCell]bool),
+        enclosed: make(map[gridCell]bool),
+    }
+    result.visited[result.cell] = true
+    return result
+}
+
+func (r *randomWalkGenerator) walled(cell gridCell) bool {
+    return r.visited[cell] || r.enclosed[cell]
+}
+
+func (r *randomWalkGenerator) outside(cell gridCell) bool {
+    return (cell.x < r.min.x) || (cell.x > r.max.x) || (cell.y < r.min.y) || (cell.y > r.max.y)
+}
+
+// openSides returns one of the free cells beside the given one, from each stretch of free cells
+// around it that are separated from each other by walls.
+func (r *randomWalkGenerator) openSides(center gridCell) []gridCell {
+    start := -1
+    for index,offset := range gridRing {
+        if r.walled(gridCell { center.x+offset.x, center.y+offset.y }) {
+            start = index
+            break
+        }
+    }
+    if start < 0 {
+        return nil
+    }
+
+    var result []gridCell
+    represented := false
+    for step := 1; step <= len(gridRing); step++ {
+        offset := gridRing[(start+step)%len(gridRing)]
+        cell := gridCell { center.x+offset.x, center.y+offset.y }
+        if r.walled(cell) {
+            represented = false
+        } else if !represented && ((offset.x == 0) || (offset.y == 0)) {
+            // NOTE: Only the cells directly beside it count, the corners don't touch it
+            result = append(result, cell)
+            represented = true
+        }
+    }
+    return result
+}
+
+// encloseCutOff fills in the pockets that have just been cut off from the outside, given a cell on
+// each side of the step that might have cut them off.
+// NOTE: Everything that the walk has walled off is connected, so stepping between two separate
+//       walls closes a loop and the sides can't reach each other any more. All but one of them
+//       are pockets, so they're searched in step with each other and the search stops as soon as
+//       the one that leads outside is known, which keeps it about as quick as filling the pockets.
+func (r *randomWalkGenerator) encloseCutOff(sides []gridCell) {
+    if len(sides) < 2 {
+        return
+    }
+    seen := make(map[gridCell]bool)
+    searches := make([][]gridCell, len(sides))
+    next := make([]int, len(sides))
+    active := make([]bool, len(sides))
+    for index,side := range sides {
+        seen[side] = true
+        searches[index] = []gridCell { side }
+        active[index] = true
+    }
+
+    remaining := len(sides)
+    escaped := false
+    for (remaining > 1) || (escaped && (remaining > 0)) {
+        for index := range searches {
+            if !active[index] {
+                continue
+            }
+            cell := searches[index][next[index]]
+            next[index]++
+            if r.outside(cell) {
+                active[index] = false
+                remaining--
+                escaped = true
+                continue
+            }
+            for _,dir := range gridDirections {
+                neighbour := gridCell { cell.x+dir.x, cell.y+dir.y }
+                if !r.walled(neighbour) && !seen[neighbour] {
+                    seen[neighbour] = true
+                    searches[index] = append(searches[index], neighbour)
+                }
+            }
+            if next[index] == len(searches[index]) {
+                for _,pocket := range searches[index] {
+                    r.enclosed[pocket] = true
+                }
+                active[index] = false
+                remaining--
+            }
+        }
+    }
+}
+
+func (r *randomWalkGenerator) NextDirection() Vec2 {
+    var candidates []gridCell
+    for _,dir := range gridDirections {
+        next := gridCell { r.cell.x+dir.x, r.cell.y+dir.y }
+        if !r.walled(next) {
+            candidates = append(candidates, dir)
+        }
+    }
+
+    r.direction = candidates[r.rng.Intn(len(candidates))]
+    r.cell = gridCell { r.cell.x+r.direction.x, r.cell.y+r.direction.y }
+    r.visited[r.cell] = true
+    r.min = gridCell { minInt(r.min.x, r.cell.x), minInt(r.min.y, r.cell.y) }
+    r.max = gridCell { maxInt(r.max.x, r.cell.x), maxInt(r.max.y, r.cell.y) }
+    r.encloseCutOff(r.openSides(r.cell))
+    return Vec2 { float64(r.direction.x), float64(r.direction.y) }
+}
+
+// listGenerator follows a hand-authored list of compass directions (N, E, S or W), starting
+// again from the beginning once it runs out.
+type listGenerator struct {
+    directions []Vec2
+    index int
+}
+
+func newListGenerator(directions string) (*listGenerator, error) {
+    result := &listGenerator {}
+    for _,dir := range strings.ToUpper(directions) {
+        switch dir {
+        case 'N':
+            result.directions = append(result.directions, Vec2 { 0.0, -1.0 })
+        case 'E':
+            result.directions = append(result.directions, Vec2 { 1.0, 0.0 })
+        case 'S':
+            result.directions = append(result.directions, Vec2 { 0.0, 1.0 })
+        case 'W':
+            result.directions = append(result.directions, Vec2 { -1.0, 0.0 })
+        case ' ', ',':
+        default:
+            return nil, fmt.Errorf("invalid path direction %q, expected one of N, E, S or W", dir)
+        }
+    }
+    if len(result.directions) == 0 {
+        return nil, fmt.Errorf("the list path generator needs at least one direction")
+    }
+    return result, nil
+}
+
+func (l *listGenerator) NextDirection() Vec2 {
+    result := l.directions[l.index%len(l.directions)]
+    l.index++
+    return result
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+    "math"
+    "testing"
+    "time"
+)
+
+// checkRandomWalk takes the given number of steps along a random path, failing if any of them go
+// back to a cell that the path has already been through.
+func checkRandomWalk(t *testing.T, seed int64, steps int) {
+    t.Helper()
+    params := defaultGameParams()
+    params.PathGenerator = "random"
+    generator, err := newPathGenerator(&params, newRNG(seed))
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    cell := gridCell {}
+    visited := map[gridCell]int { cell: 0 }
+    for step := 1; step <= steps; step++ {
+        direction := generator.NextDirection()
+        cell.x += int(math.Round(direction.x))
+        cell.y += int(math.Round(direction.y))
+        if previous,ok := visited[cell]; ok {
+            t.Fatalf("with seed %d, step %d went back to %v from step %d", seed, step, cell, previous)
+        }
+        visited[cell] = step
+    }
+}
+
+func TestRandomWalkNeverRevisitsACell(t *testing.T) {
+    for seed := int64(1); seed <= 20; seed++ {
+        checkRandomWalk(t, seed, 600)
+    }
+}
+
+// NOTE: Each step used to search for a way out from every cell beside it, which got slower the longer
+//       the path was, to the point where 5000 steps took seconds. Now it should be well under one.
+func TestLongRandomWalksStayQuick(t *testing.T) {
+    start := time.Now()
+    for seed := int64(1); seed <= 3; seed++ {
+        checkRandomWalk(t, seed, 5000)
+    }
+    if elapsed := time.Since(start); elapsed > time.Second {
+        t.Errorf("3 random walks of 5000 steps took %v", elapsed)
+    }
+}
@@ -0,0 +1,116 @@
+// +build headless
+
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "io/ioutil"
+)
+
+const regressionVersion = 2
+
+// RegressionMetrics is everything about how a bot game went that gets compared against the baseline.
+// NOTE: The simulation is deterministic, so these have to match exactly. Any change to them at all
+//       means that the change being tested affects how the game plays.
+type RegressionMetrics struct {
+    WaveReached int `json:"waveReached"`
+    Killed int `json:"killed"`
+    Leaked int `json:"leaked"`
+    LivesLeft int `json:"livesLeft"`
+    Ticks int `json:"ticks"` // NOTE: The tick that the game was lost on, or how long it lasted if it wasn't
+}
+
+// RegressionCase is one simulated game in the regression run, along with how it is expected to go.
+type RegressionCase struct {
+    Strategy string `json:"strategy"`
+    Seed int64 `json:"seed,omitempty"`
+    RegressionMetrics
+}
+
+// RegressionBaseline is the set of bot games that get played with the default GameParams to check
+// that changes to the simulation haven't changed the game by accident.
+// NOTE: If a change is meant to shift the balance, re-record the baseline with -regress-update
+type RegressionBaseline struct {
+    Version int `json:"version"`
+    WaveCount int `json:"waveCount"`
+    Cases []RegressionCase `json:"cases"`
+}
+
+func (m RegressionMetrics) String() string {
+    return fmt.Sprintf("wave %d, %d killed, %d leaked, %d lives left after %d ticks",
+                       m.WaveReached, m.Killed, m.Leaked, m.LivesLeft, m.Ticks)
+}
+
+func loadRegressionBaseline(path string) (*RegressionBaseline, error) {
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    result := &RegressionBaseline {}
+    if err := json.Unmarshal(data, result); err != nil {
+        return nil, err
+    }
+    if result.Version != regressionVersion {
+        return nil, fmt.Errorf("regression baseline version %d is not supported, only version %d is", result.Version, regressionVersion)
+    }
+    if result.WaveCount <= 0 {
+        return nil, fmt.Errorf("regression baseline has a non-positive wave count")
+    }
+    for _,regressionCase := range result.Cases {
+        if _,err := newStrategy(regressionCase.Strategy, regressionCase.Seed); err != nil {
+            return nil, err
+        }
+    }
+    return result, nil
+}
+
+func (b *RegressionBaseline) WriteFile(path string) error {
+    data, err := json.MarshalIndent(b, "", "  ")
+    if err != nil {
+        return err
+    }
+    return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// runRegression plays every game in the baseline and reports whether they all went exactly the way
+// that the baseline says they should. If update is set then the baseline is instead changed to match
+// the results.
+func runRegression(baseline *RegressionBaseline, update bool, out io.Writer) bool {
+    passed := true
+    for index := range baseline.Cases {
+        regressionCase := &baseline.Cases[index]
+        strategy, _ := newStrategy(regressionCase.Strategy, regressionCase.Seed)
+        result := runSimulation(defaultGameParams(), strategy, baseline.WaveCount, ioutil.Discard)
+        metrics := RegressionMetrics {
+            WaveReached: result.waveReached,
+            Killed: result.killed,
+            Leaked: result.leaked,
+            LivesLeft: result.lives,
+            Ticks: result.ticks,
+        }
+
+        name := regressionCase.Strategy
+        if regressionCase.Seed != 0 {
+            name = fmt.Sprintf("%s (seed %d)", name, regressionCase.Seed)
+        }
+        if update {
+            fmt.Fprintf(out, "%-24s %v (was %v)\n", name, metrics, regressionCase.RegressionMetrics)
+            regressionCase.RegressionMetrics = metrics
+            continue
+        }
+
+        switch {
+        case result.stuck:
+            fmt.Fprintf(out, "%-24s %v FAIL (stuck)\n", name, metrics)
+            passed = false
+        case metrics != regressionCase.RegressionMetrics:
+            fmt.Fprintf(out, "%-24s %v FAIL (expected %v)\n", name, metrics, regressionCase.RegressionMetrics)
+            passed = false
+        default:
+            fmt.Fprintf(out, "%-24s %v ok\n", name, metrics)
+        }
+    }
+    return passed
+}
@@ -0,0 +1,19 @@
+// +build headless
+
+package main
+
+import (
+    "bytes"
+    "testing"
+)
+
+func TestRegression(t *testing.T) {
+    baseline, err := loadRegressionBaseline("regression.json")
+    if err != nil {
+        t.Fatalf("failed to load the regression baseline: %v", err)
+    }
+    var out bytes.Buffer
+    if !runRegression(baseline, false, &out) {
+        t.Errorf("the bot games didn't go the way that the baseline says (re-record it with -regress-update if that's intended):\n%s", out.String())
+    }
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+)
+
+const replayVersion = 7
+
+// ReplayInput is a single tick's worth of Input that actually did something.
+// NOTE: Ticks with no input at all are not stored, and the cursor only matters on the ticks where
+//       we clicked (that's the only time the simulation reads it) so we only store it for those.
+type ReplayInput struct {
+    Tick int `json:"tick"`
+    StartWave bool `json:"startWave,omitempty"`
+    ToggleGhost bool `json:"toggleGhost,omitempty"`
+    Restart bool `json:"restart,omitempty"`
+    Click bool `json:"click,omitempty"`
+    SelectTower int `json:"selectTower,omitempty"`
+    Upgrade bool `json:"upgrade,omitempty"`
+    Sell bool `json:"sell,omitempty"`
+    CycleTargeting bool `json:"cycleTargeting,omitempty"`
+    CursorX float64 `json:"cursorX,omitempty"`
+    CursorY float64 `json:"cursorY,omitempty"`
+}
+
+type Replay struct {
+    Version int `json:"version"`
+    Params GameParams `json:"params"`
+    Start *SaveGame `json:"start,omitempty"` // NOTE: Only set if the recording started from a loaded game
+    TickCount int `json:"tickCount"`
+    Inputs []ReplayInput `json:"inputs"`
+}
+
+func loadReplay(path string) (*Replay, error) {
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+
+    result := &Replay {}
+    if err := json.Unmarshal(data, result); err != nil {
+        return nil, fmt.Errorf("failed to parse replay %s: %v", path, err)
+    }
+    if result.Version != replayVersion {
+        return nil, fmt.Errorf("replay %s has version %d, but only version %d is supported",
+                               path, result.Version, replayVersion)
+    }
+    if err := result.Params.validate(); err != nil {
+        return nil, fmt.Errorf("replay %s has invalid params: %v", path, err)
+    }
+    if result.Start != nil {
+        if err := result.Start.validate(); err != nil {
+            return nil, fmt.Errorf("replay %s starts from a save game that %v", path, err)
+        }
+    }
+    return result, nil
+}
+
+func (r *Replay) Save(path string) error {
+    data, err := json.Marshal(r)
+    if err != nil {
+        return err
+    }
+    return ioutil.WriteFile(path, data, 0644)
+}
+
+// ReplayRecorder captures the input for every tick that gets passed to GameState.Step.
+type ReplayRecorder struct {
+    replay Replay
+}
+
+func newReplayRecorder(params GameParams, start *SaveGame) *ReplayRecorder {
+    return &ReplayRecorder {
+        replay: Replay {
+            Version: replayVersion,
+            Params: params,
+            Start: start,
+        },
+    }
+}
+
+func (r *ReplayRecorder) Record(input Input) {
+    tick := r.replay.TickCount
+    r.replay.TickCount++
+    if !input.startWave && !input.toggleGhost && !input.restart && !input.click && (input.selectTower == 0) &&
+       !input.upgrade && !input.sell && !input.cycleTargeting {
+        return
+    }
+
+    recorded := ReplayInput {
+        Tick: tick,
+        StartWave: input.startWave,
+        ToggleGhost: input.toggleGhost,
+        Restart: input.restart,
+        Click: input.click,
+        SelectTower: input.selectTower,
+        Upgrade: input.upgrade,
+        Sell: input.sell,
+        CycleTargeting: input.cycleTargeting,
+    }
+    if input.click {
+        recorded.CursorX = input.cursorLoc.x
+        recorded.CursorY = input.cursorLoc.y
+    }
+    r.replay.Inputs = append(r.replay.Inputs, recorded)
+}
+
+// Replay returns everything that has been recorded so far.
+func (r *ReplayRecorder) Replay() *Replay {
+    return &r.replay
+}
+
+func (r *ReplayRecorder) Save(path string) error {
+    return r.replay.Save(path)
+}
+
+// ReplayPlayer feeds a recorded Replay back out one tick at a time.
+type ReplayPlayer struct {
+    replay *Replay
+    tick int
+    nextInput int
+    cursorLoc Vec2
+}
+
+// newGameStateFromReplay creates a GameState in the same starting state that the replay was recorded from.
+func newGameStateFromReplay(replay *Replay) *GameState {
+    result := newGameState(replay.Params)
+    if replay.Start != nil {
+        result.Load(replay.Start)
+    }
+    return result
+}
+
+func newReplayPlayer(replay *Replay) *ReplayPlayer {
+    return &ReplayPlayer {
+        replay: replay,
+    }
+}
+
+func (p *ReplayPlayer) Finished() bool {
+    return p.tick >= p.replay.TickCount
+}
+
+// Next returns the input for the next tick of the replay. Once the replay has finished it just
+// keeps returning empty input (with the cursor wherever it was last).
+func (p *ReplayPlayer) Next() Input {
+    result := Input {}
+    if p.Finished() {
+        result.cursorLoc = p.cursorLoc
+        return result
+    }
+
+    inputs := p.replay.Inputs
+    if (p.nextInput < len(inputs)) && (inputs[p.nextInput].Tick == p.tick) {
+        recorded := inputs[p.nextInput]
+        result.startWave = recorded.StartWave
+        result.toggleGhost = recorded.ToggleGhost
+        result.restart = recorded.Restart
+        result.click = recorded.Click
+        result.selectTower = recorded.SelectTower
+        result.upgrade = recorded.Upgrade
+        result.sell = recorded.Sell
+        result.cycleTargeting = recorded.CycleTargeting
+        if recorded.Click {
+            p.cursorLoc = Vec2 { recorded.CursorX, recorded.CursorY }
+        }
+        p.nextInput++
+    }
+    result.cursorLoc = p.cursorLoc
+    p.tick++
+    return result
+}
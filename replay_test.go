@@ -0,0 +1,164 @@
+// +build headless
+
+package main
+
+import (
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+// fiddlingStrategy plays like the strategy it wraps, but every so often it stops in between waves
+// to select one of its towers, upgrade it and change its targeting, and it restarts whenever it
+// loses, so that the game gets every kind of input.
+type fiddlingStrategy struct {
+    Strategy
+    tick int
+    pending []Input
+}
+
+func (s *fiddlingStrategy) NextInput(g *GameState) Input {
+    s.tick++
+    if len(s.pending) > 0 {
+        result := s.pending[0]
+        s.pending = s.pending[1:]
+        return result
+    }
+    if g.lives == 0 {
+        return Input { restart: true }
+    }
+    if (s.tick%300 == 0) && (len(g.towers) > 0) && g.canStartWave() {
+        tower := g.towers[(s.tick/300) % len(g.towers)]
+        s.pending = []Input {
+            { upgrade: true },
+            { cycleTargeting: true },
+            { click: true, cursorLoc: tower.position },
+        }
+        return Input { click: true, cursorLoc: tower.position }
+    }
+    return s.Strategy.NextInput(g)
+}
+
+func newFiddlingStrategy(t *testing.T) *fiddlingStrategy {
+    t.Helper()
+    strategy, err := newStrategy("random", 3)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return &fiddlingStrategy { Strategy: strategy }
+}
+
+// playRecorded steps the game with the strategy's input for the given number of ticks, recording
+// the input for every one of them the same way that the game does.
+func playRecorded(g *GameState, strategy Strategy, recorder *ReplayRecorder, ticks int) {
+    for tick := 0; tick < ticks; tick++ {
+        input := strategy.NextInput(g)
+        if recorder != nil {
+            recorder.Record(input)
+        }
+        g.Step(input)
+    }
+}
+
+// playBack writes the replay out and reads it back in, then plays it from the start in a new game.
+func playBack(t *testing.T, replay *Replay) *GameState {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), "replay.json")
+    if err := replay.Save(path); err != nil {
+        t.Fatalf("failed to save the replay: %v", err)
+    }
+    loaded, err := loadReplay(path)
+    if err != nil {
+        t.Fatalf("failed to load the replay: %v", err)
+    }
+
+    g := newGameStateFromReplay(loaded)
+    player := newReplayPlayer(loaded)
+    for !player.Finished() {
+        g.Step(player.Next())
+    }
+    return g
+}
+
+func TestReplayPlaysBack(t *testing.T) {
+    params := defaultGameParams()
+    params.Lives = 5
+    params.Seed = 11
+    params.PathGenerator = "random"
+    g := newGameState(params)
+    strategy := newFiddlingStrategy(t)
+    recorder := newReplayRecorder(params, nil)
+
+    // NOTE: Play until the game has been lost and restarted (with a new seed), and then for a bit more
+    firstGameWave := 0
+    for g.params.Seed == params.Seed {
+        if recorder.Replay().TickCount > 60*60*60 {
+            t.Fatalf("the game still hadn't been lost after an hour")
+        }
+        firstGameWave = g.currentWave
+        playRecorded(g, strategy, recorder, 1)
+    }
+    playRecorded(g, strategy, recorder, 60*60*2)
+    if (firstGameWave < 3) || (g.runStats.TowersBuilt == 0) {
+        t.Fatalf("the strategy hardly played (wave %d, then %+v), so the replay doesn't test much", firstGameWave, g.runStats)
+    }
+
+    played := playBack(t, recorder.Replay())
+    if expected, actual := snapshot(t, g), snapshot(t, played); expected != actual {
+        t.Errorf("the replay ended up somewhere else than the game it was recorded from:\n%s\n\nvs\n\n%s", expected, actual)
+    }
+}
+
+func TestReplayPlaysBackFromSave(t *testing.T) {
+    params := defaultGameParams()
+    params.Seed = 5
+    g := newGameState(params)
+    strategy := newFiddlingStrategy(t)
+    playRecorded(g, strategy, nil, 60*60*2)
+    for !g.canSave() {
+        g.Step(strategy.NextInput(g))
+    }
+    if g.currentWave == 0 {
+        t.Fatalf("the game hasn't got past the first wave, so the save doesn't test much")
+    }
+
+    recorder := newReplayRecorder(g.params, g.Save())
+    playRecorded(g, strategy, recorder, 60*60*2)
+
+    played := playBack(t, recorder.Replay())
+    if expected, actual := snapshot(t, g), snapshot(t, played); expected != actual {
+        t.Errorf("the replay ended up somewhere else than the game it was recorded from:\n%s\n\nvs\n\n%s", expected, actual)
+    }
+}
+
+func TestReplayWithBrokenStartDoesNotLoad(t *testing.T) {
+    g := newGameState(defaultGameParams())
+    build(t, g, findBuildSite(t, g, 3))
+    breakages := []struct {
+        name string
+        breakSave func(save *SaveGame)
+    } {
+        { "version", func(save *SaveGame) { save.Version = saveVersion+1 } },
+        { "difficulty", func(save *SaveGame) { save.Params.Difficulty = "impossible" } },
+        { "waypoints", func(save *SaveGame) { save.Waypoints = nil } },
+        { "tower type", func(save *SaveGame) { save.Towers[0].Type = len(towerTypes) } },
+        { "selected tower", func(save *SaveGame) { save.SelectedTower = -1 } },
+        { "tower costs", func(save *SaveGame) { save.TowerCosts = save.TowerCosts[:1] } },
+    }
+    for _,breakage := range breakages {
+        save := g.Save()
+        breakage.breakSave(save)
+        recorder := newReplayRecorder(g.params, save)
+        recorder.Record(Input { startWave: true })
+
+        path := filepath.Join(t.TempDir(), "replay.json")
+        if err := recorder.Replay().Save(path); err != nil {
+            t.Fatalf("failed to save the replay: %v", err)
+        }
+        if _,err := loadReplay(path); err == nil {
+            t.Errorf("a replay starting from a save with a broken %s loaded anyway", breakage.name)
+        } else if !strings.Contains(err.Error(), "starts from a save game") {
+            t.Errorf("a replay starting from a save with a broken %s failed for the wrong reason: %v", breakage.name, err)
+        }
+    }
+}
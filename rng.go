@@ -0,0 +1,66 @@
+package main
+
+import (
+    "encoding/json"
+)
+
+// RNG is where all of the game's randomness comes from. It's a splitmix64 generator rather than
+// math/rand, because its entire state is a single number that can be written into saves.
+// NOTE: Everything random has to go through the game's own RNG (and never the global one), or
+//       replays and saves would stop reproducing the game that they came from
+type RNG struct {
+    state uint64
+}
+
+// NOTE: Seeds that the game picks for itself stay below this, so that they're easy to pass around
+const maxPickedSeed = 1000000
+
+func newRNG(seed int64) RNG {
+    return RNG { state: uint64(seed) }
+}
+
+func (r *RNG) next() uint64 {
+    r.state += 0x9E3779B97F4A7C15
+    result := r.state
+    result = (result ^ (result >> 30)) * 0xBF58476D1CE4E5B9
+    result = (result ^ (result >> 27)) * 0x94D049BB133111EB
+    return result ^ (result >> 31)
+}
+
+// Float64 returns a number in [0, 1).
+func (r *RNG) Float64() float64 {
+    return float64(r.next() >> 11)/float64(1 << 53)
+}
+
+// Intn returns a number in [0, n), which n must be positive for.
+func (r *RNG) Intn(n int) int {
+    return int(r.next() % uint64(n))
+}
+
+// Chance returns true with the given probability.
+func (r *RNG) Chance(probability float64) bool {
+    return r.Float64() < probability
+}
+
+// Jitter scales the value by a random amount of up to the given fraction either way.
+func (r *RNG) Jitter(value float64, fraction float64) float64 {
+    return value*(1.0 + fraction*(2.0*r.Float64() - 1.0))
+}
+
+// Split returns a new generator that can be used independently of this one.
+func (r *RNG) Split() RNG {
+    return RNG { state: r.next() }
+}
+
+// PickSeed returns a new seed for a game, from 1 up to (but not including) maxPickedSeed.
+func (r *RNG) PickSeed() int64 {
+    return 1 + int64(r.Intn(maxPickedSeed-1))
+}
+
+func (r RNG) MarshalJSON() ([]byte, error) {
+    return json.Marshal(r.state)
+}
+
+func (r *RNG) UnmarshalJSON(data []byte) error {
+    return json.Unmarshal(data, &r.state)
+}
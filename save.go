@@ -0,0 +1,221 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+)
+
+const saveVersion = 9
+
+type SavedTower struct {
+    Position Vec2 `json:"position"`
+    Scale float64 `json:"scale"`
+    Type int `json:"type"`
+    Level int `json:"level"`
+    Spent int `json:"spent"`
+    TargetMode TargetMode `json:"targetMode"`
+}
+
+// SaveGame is everything needed to resume a game in between waves.
+// NOTE: There are never any enemies or projectiles around in between waves, so we don't store those
+type SaveGame struct {
+    Version int `json:"version"`
+    Params GameParams `json:"params"`
+
+    Waypoints []Vec2 `json:"waypoints"`
+    PathBoundingBox Rect `json:"pathBoundingBox"`
+    Camera Rect `json:"camera"`
+
+    Towers []SavedTower `json:"towers"`
+    TowerCosts []int `json:"towerCosts"`
+    TowerScale float64 `json:"towerScale"`
+    SelectedTower int `json:"selectedTower"`
+
+    Credits int `json:"credits"`
+    Lives int `json:"lives"`
+    CurrentWave int `json:"currentWave"`
+    EnemyHealth float64 `json:"enemyHealth"`
+    EnemySpeed float64 `json:"enemySpeed"`
+    EnemyBounty int `json:"enemyBounty"`
+    ProjectileSpeed float64 `json:"projectileSpeed"`
+
+    Stats RunStats `json:"stats"`
+    RNG RNG `json:"rng"`
+}
+
+func (v Vec2) MarshalJSON() ([]byte, error) {
+    return json.Marshal([2]float64 { v.x, v.y })
+}
+
+func (v *Vec2) UnmarshalJSON(data []byte) error {
+    var coords [2]float64
+    if err := json.Unmarshal(data, &coords); err != nil {
+        return err
+    }
+    v.x = coords[0]
+    v.y = coords[1]
+    return nil
+}
+
+type jsonRect struct {
+    Position Vec2 `json:"position"`
+    Size Vec2 `json:"size"`
+}
+
+func (r Rect) MarshalJSON() ([]byte, error) {
+    return json.Marshal(jsonRect { r.position, r.size })
+}
+
+func (r *Rect) UnmarshalJSON(data []byte) error {
+    var result jsonRect
+    if err := json.Unmarshal(data, &result); err != nil {
+        return err
+    }
+    r.position = result.Position
+    r.size = result.Size
+    return nil
+}
+
+// canSave reports whether we're in between waves, which is the only time that saving is allowed.
+func (g *GameState) canSave() bool {
+    return g.canStartWave()
+}
+
+func (g *GameState) Save() *SaveGame {
+    result := &SaveGame {
+        Version: saveVersion,
+        Params: g.params,
+
+        Waypoints: append([]Vec2 {}, g.waypoints...),
+        PathBoundingBox: g.pathBoundingBox,
+        Camera: g.camera,
+
+        Towers: make([]SavedTower, 0, len(g.towers)),
+        TowerCosts: append([]int {}, g.towerCosts...),
+        TowerScale: g.ghostTower.scale,
+        SelectedTower: g.ghostTower.towerType,
+
+        Credits: g.credits,
+        Lives: g.lives,
+        CurrentWave: g.currentWave,
+        EnemyHealth: g.enemyHealth,
+        EnemySpeed: g.enemySpeed,
+        EnemyBounty: g.enemyBounty,
+        ProjectileSpeed: g.projectileSpeed,
+
+        Stats: g.runStats,
+        RNG: g.rng,
+    }
+    for _,tower := range g.towers {
+        result.Towers = append(result.Towers, SavedTower { tower.position, tower.scale, tower.towerType, tower.level, tower.spent, tower.targetMode })
+    }
+    return result
+}
+
+func (g *GameState) Load(save *SaveGame) {
+    g.params = save.Params
+    g.Reset()
+
+    g.waypoints = append(g.waypoints[:0], save.Waypoints...)
+    g.targetWaypointCount = len(g.waypoints)
+    g.waypointsReady = true
+    g.pathEndLocation = g.waypoints[len(g.waypoints)-1]
+    // NOTE: The generators are deterministic, so we can get back to where we were by just asking
+    //       for the same number of segments again. Starting the RNG over gives the random ones
+    //       the same numbers that they got the first time around.
+    g.rng = newRNG(g.params.Seed)
+    g.pathGenerator = g.mustNewPathGenerator()
+    for i := 1; i < len(g.waypoints); i++ {
+        g.pathGenerator.NextDirection()
+    }
+    g.pathBoundingBox = save.PathBoundingBox
+    g.camera = save.Camera
+
+    for _,tower := range save.Towers {
+        g.towers = append(g.towers, &Tower {
+            position: tower.Position,
+            scale: tower.Scale,
+            rangeScale: g.params.TowerRangeScale,
+            towerType: tower.Type,
+            level: tower.Level,
+            spent: tower.Spent,
+            targetMode: tower.TargetMode,
+        })
+    }
+    copy(g.towerCosts, save.TowerCosts)
+    g.ghostTower.scale = save.TowerScale
+    g.selectTower(save.SelectedTower)
+
+    g.credits = save.Credits
+    g.lives = save.Lives
+    g.currentWave = save.CurrentWave
+    g.enemyHealth = save.EnemyHealth
+    g.enemySpeed = save.EnemySpeed
+    g.enemyBounty = save.EnemyBounty
+    g.projectileSpeed = save.ProjectileSpeed
+    g.runStats = save.Stats
+    g.rng = save.RNG
+}
+
+func (s *SaveGame) WriteFile(path string) error {
+    data, err := json.Marshal(s)
+    if err != nil {
+        return err
+    }
+    return ioutil.WriteFile(path, data, 0644)
+}
+
+func loadSaveGame(path string) (*SaveGame, error) {
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+
+    result := &SaveGame {}
+    if err := json.Unmarshal(data, result); err != nil {
+        return nil, fmt.Errorf("failed to parse save game %s: %v", path, err)
+    }
+    if err := result.validate(); err != nil {
+        return nil, fmt.Errorf("save game %s %v", path, err)
+    }
+    return result, nil
+}
+
+// validate checks that the save can be loaded without GameState.Load tripping over it, whether it
+// came from a save file or from the start of a replay. The errors read on from the name of the save.
+func (s *SaveGame) validate() error {
+    if s.Version != saveVersion {
+        return fmt.Errorf("has version %d, but only version %d is supported", s.Version, saveVersion)
+    }
+    if err := s.Params.validate(); err != nil {
+        return fmt.Errorf("has invalid params: %v", err)
+    }
+    if len(s.Waypoints) < 2 {
+        return fmt.Errorf("has an invalid path with only %d waypoints", len(s.Waypoints))
+    }
+    if len(s.TowerCosts) != len(towerTypes) {
+        return fmt.Errorf("has costs for %d tower types, but there are %d", len(s.TowerCosts), len(towerTypes))
+    }
+    if (s.SelectedTower < 0) || (s.SelectedTower >= len(towerTypes)) {
+        return fmt.Errorf("has an invalid selected tower type %d", s.SelectedTower)
+    }
+    if !s.Params.allowsTower(s.SelectedTower) {
+        return fmt.Errorf("has a selected tower type %d that its params don't allow", s.SelectedTower)
+    }
+    for _,tower := range s.Towers {
+        if (tower.Type < 0) || (tower.Type >= len(towerTypes)) {
+            return fmt.Errorf("has a tower with invalid type %d", tower.Type)
+        }
+        if (tower.Level < 0) || (tower.Level > len(towerUpgrades)) {
+            return fmt.Errorf("has a tower with invalid level %d", tower.Level)
+        }
+        if (tower.TargetMode < 0) || (tower.TargetMode >= targetModeCount) {
+            return fmt.Errorf("has a tower with invalid targeting mode %d", tower.TargetMode)
+        }
+    }
+    if s.Lives <= 0 {
+        return fmt.Errorf("has no lives remaining")
+    }
+    return nil
+}
@@ -0,0 +1,54 @@
+// +build headless
+
+package main
+
+import (
+    "path/filepath"
+    "testing"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+    for _,generator := range []string { "dragon", "random" } {
+        t.Run(generator, func(t *testing.T) {
+            params := defaultGameParams()
+            params.PathGenerator = generator
+            params.Seed = 23
+            params.Lives = 20
+            g := newGameState(params)
+            strategy := newFiddlingStrategy(t)
+            for (g.currentWave < 3) || !g.canSave() {
+                if g.lives == 0 {
+                    t.Fatalf("the game was lost before wave 3, so the save doesn't test much")
+                }
+                g.Step(strategy.NextInput(g))
+            }
+
+            path := filepath.Join(t.TempDir(), "save.json")
+            if err := g.Save().WriteFile(path); err != nil {
+                t.Fatalf("failed to save the game: %v", err)
+            }
+            save, err := loadSaveGame(path)
+            if err != nil {
+                t.Fatalf("failed to load the game: %v", err)
+            }
+            loaded := newGameState(defaultGameParams())
+            loaded.Load(save)
+            if expected, actual := snapshot(t, g), snapshot(t, loaded); expected != actual {
+                t.Fatalf("the loaded game isn't the one that was saved:\n%s\n\nvs\n\n%s", expected, actual)
+            }
+
+            // NOTE: The path has to keep growing the same way, and the RNG has to carry on from the same place
+            for ticks := 0; (g.lives > 0) && ((g.currentWave < save.CurrentWave+2) || !g.canSave()); ticks++ {
+                if ticks >= 2*maxWaveTicks {
+                    t.Fatalf("the game still hadn't finished two more waves after %d ticks", ticks)
+                }
+                input := strategy.NextInput(g)
+                g.Step(input)
+                loaded.Step(input)
+            }
+            if expected, actual := snapshot(t, g), snapshot(t, loaded); expected != actual {
+                t.Errorf("the loaded game played out differently from the one that was saved:\n%s\n\nvs\n\n%s", expected, actual)
+            }
+        })
+    }
+}
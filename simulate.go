@@ -0,0 +1,80 @@
+// +build headless
+
+package main
+
+import (
+    "fmt"
+    "io"
+    "text/tabwriter"
+)
+
+// NOTE: If a wave goes on for longer than this then something has gone wrong (enemies stuck on the
+//       path, a strategy that never starts the next wave) and we'd rather stop than hang forever.
+const simulationMaxTicksPerWave = 60*60*60
+
+// SimulationResult is how far a simulated game got.
+type SimulationResult struct {
+    waveReached int
+    lives int
+    killed int
+    leaked int
+    ticks int // NOTE: Up until the game was lost, if it was
+    stuck bool
+    waves []WaveStats
+}
+
+// runSimulation plays a game with the given parameters and strategy until it has finished the given
+// number of waves (or run out of lives), writing a table of statistics for each wave to out.
+func runSimulation(params GameParams, strategy Strategy, waveCount int, out io.Writer) SimulationResult {
+    g := newGameState(params)
+    result := SimulationResult {}
+
+    table := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintln(table, "Wave\tSpawned\tKilled\tLeaked\tEarned\tShots\tHits\tHit%\tLives\tCredits\tTowers\tEnemy HP\tEnemy speed\tBasic cost\t")
+
+    ticksThisWave := 0
+    for (g.lives > 0) && !((g.currentWave >= waveCount) && !g.waveInProgress) {
+        wasInProgress := g.waveInProgress
+        g.Step(strategy.NextInput(g))
+
+        ticksThisWave++
+        if g.waveInProgress && !wasInProgress {
+            ticksThisWave = 0
+        }
+        if ticksThisWave > simulationMaxTicksPerWave {
+            result.stuck = true
+            break
+        }
+        if !wasInProgress || g.waveInProgress {
+            continue
+        }
+
+        stats := g.waveStats
+        result.waves = append(result.waves, stats)
+        result.leaked += stats.leaked
+        hitPercent := 0.0
+        if stats.shotsFired > 0 {
+            hitPercent = 100.0*float64(stats.shotsHit)/float64(stats.shotsFired)
+        }
+        fmt.Fprintf(table, "%d\t%d\t%d\t%d\t%d\t%d\t%d\t%.0f\t%d\t%d\t%d\t%.1f\t%.1f\t%d\t\n",
+                    g.currentWave, stats.spawned, stats.killed, stats.leaked, stats.creditsEarned,
+                    stats.shotsFired, stats.shotsHit, hitPercent, g.lives, g.credits, len(g.towers),
+                    g.enemyHealth, g.enemySpeed, g.towerCosts[0])
+    }
+    table.Flush()
+
+    result.waveReached = g.currentWave
+    result.lives = g.lives
+    result.killed = g.runStats.Killed
+    result.ticks = g.runStats.Ticks
+    switch {
+    case result.stuck:
+        fmt.Fprintf(out, "Gave up on wave %d after it ran for %d ticks", g.currentWave, simulationMaxTicksPerWave)
+    case g.lives == 0:
+        fmt.Fprintf(out, "Ran out of lives on wave %d", g.currentWave)
+    default:
+        fmt.Fprintf(out, "Survived %d waves with %d lives left", g.currentWave, g.lives)
+    }
+    fmt.Fprintf(out, " (%s)\n", params.DifficultyLabel())
+    return result
+}
@@ -0,0 +1,195 @@
+package main
+
+import "math"
+
+// NOTE: All of the sounds are generated when the game starts rather than being loaded from files,
+//       as 16-bit little-endian stereo PCM, which is what ebiten's audio players take
+const audioSampleRate = 44100
+
+type SoundID int
+
+const (
+    soundTowerFire SoundID = iota
+    soundEnemyDeath
+    soundEnemyLeak
+    soundWaveStart
+    soundPathGrow
+
+    soundCount
+)
+
+type Waveform int
+
+const (
+    waveSine Waveform = iota
+    waveSquare
+    waveTriangle
+    waveNoise
+)
+
+// Tone is a single note that sweeps from one frequency to another, with a short attack and then
+// an exponential decay.
+type Tone struct {
+    waveform Waveform
+    start float64 // NOTE: Seconds from the start of the sound
+    duration float64
+    fromFrequency float64
+    toFrequency float64
+    volume float64
+    decay float64 // NOTE: How many times per second the volume falls by a factor of e
+}
+
+var soundTones = [soundCount][]Tone {
+    soundTowerFire: {
+        { waveform: waveSquare, duration: 0.07, fromFrequency: 900.0, toFrequency: 450.0, volume: 0.25, decay: 30.0 },
+    },
+    soundEnemyDeath: {
+        { waveform: waveTriangle, duration: 0.25, fromFrequency: 600.0, toFrequency: 120.0, volume: 0.5, decay: 10.0 },
+        { waveform: waveNoise, duration: 0.1, volume: 0.15, decay: 30.0 },
+    },
+    soundEnemyLeak: {
+        { waveform: waveSquare, duration: 0.4, fromFrequency: 110.0, toFrequency: 80.0, volume: 0.35, decay: 4.0 },
+        { waveform: waveSquare, start: 0.05, duration: 0.35, fromFrequency: 116.0, toFrequency: 84.0, volume: 0.25, decay: 4.0 },
+    },
+    soundWaveStart: {
+        { waveform: waveTriangle, duration: 0.15, fromFrequency: 440.0, toFrequency: 440.0, volume: 0.5, decay: 6.0 },
+        { waveform: waveTriangle, start: 0.15, duration: 0.3, fromFrequency: 660.0, toFrequency: 660.0, volume: 0.5, decay: 6.0 },
+    },
+    soundPathGrow: {
+        { waveform: waveSine, duration: 0.3, fromFrequency: 200.0, toFrequency: 400.0, volume: 0.3, decay: 8.0 },
+    },
+}
+
+// pcmBuffer is a mono mix of samples that gets converted to stereo PCM at the end.
+type pcmBuffer struct {
+    samples []float64
+    noiseState uint32
+}
+
+func newPCMBuffer(duration float64) *pcmBuffer {
+    return &pcmBuffer {
+        samples: make([]float64, int(math.Ceil(duration*audioSampleRate))),
+        noiseState: 1,
+    }
+}
+
+// noise returns a value from -1 to 1, from a simple xorshift generator so that the sounds come out
+// the same every time.
+func (b *pcmBuffer) noise() float64 {
+    b.noiseState ^= b.noiseState << 13
+    b.noiseState ^= b.noiseState >> 17
+    b.noiseState ^= b.noiseState << 5
+    return float64(b.noiseState)/float64(math.MaxUint32)*2.0 - 1.0
+}
+
+func (b *pcmBuffer) addTone(tone Tone) {
+    first := int(tone.start*audioSampleRate)
+    count := int(tone.duration*audioSampleRate)
+    phase := 0.0
+    for i := 0; (i < count) && (first+i < len(b.samples)); i++ {
+        t := float64(i)/audioSampleRate
+        progress := float64(i)/float64(count)
+        frequency := tone.fromFrequency + (tone.toFrequency-tone.fromFrequency)*progress
+        phase = math.Mod(phase + frequency/audioSampleRate, 1.0)
+
+        var value float64
+        switch tone.waveform {
+        case waveSine:
+            value = math.Sin(2.0*math.Pi*phase)
+        case waveSquare:
+            value = 1.0
+            if phase >= 0.5 {
+                value = -1.0
+            }
+        case waveTriangle:
+            value = 4.0*math.Abs(phase-0.5) - 1.0
+        case waveNoise:
+            value = b.noise()
+        }
+
+        // NOTE: A few milliseconds of attack and release stop the sound from clicking at either end
+        envelope := math.Exp(-tone.decay*t)
+        envelope *= math.Min(1.0, t/0.003)
+        envelope *= math.Min(1.0, (tone.duration-t)/0.003)
+        b.samples[first+i] += tone.volume*envelope*value
+    }
+}
+
+func (b *pcmBuffer) PCM() []byte {
+    result := make([]byte, 4*len(b.samples))
+    for i,sample := range b.samples {
+        value := int16(math.Max(-1.0, math.Min(1.0, sample))*math.MaxInt16)
+        for channel := 0; channel < 2; channel++ {
+            result[4*i + 2*channel] = byte(value)
+            result[4*i + 2*channel + 1] = byte(uint16(value) >> 8)
+        }
+    }
+    return result
+}
+
+func synthesizeSound(sound SoundID) []byte {
+    duration := 0.0
+    for _,tone := range soundTones[sound] {
+        duration = math.Max(duration, tone.start+tone.duration)
+    }
+    buffer := newPCMBuffer(duration)
+    for _,tone := range soundTones[sound] {
+        buffer.addTone(tone)
+    }
+    return buffer.PCM()
+}
+
+// NOTE: The music is a loop of arpeggiated chords over a bass note, as semitones above A2 (110Hz)
+const (
+    musicNoteLength = 0.25
+    musicNotesPerChord = 8
+)
+
+var musicChords = [...][3]int {
+    { 0, 3, 7 }, // NOTE: A minor
+    { -4, 0, 3 }, // NOTE: F major
+    { 3, 7, 10 }, // NOTE: C major
+    { -2, 2, 5 }, // NOTE: G major
+}
+
+func semitoneFrequency(semitones int) float64 {
+    return 110.0*math.Pow(2.0, float64(semitones)/12.0)
+}
+
+// synthesizeMusic returns a single pass of the background music, which loops seamlessly.
+func synthesizeMusic() []byte {
+    chordLength := musicNoteLength*musicNotesPerChord
+    buffer := newPCMBuffer(chordLength*float64(len(musicChords)))
+    arpeggio := [musicNotesPerChord]int { 0, 1, 2, 1, 0, 1, 2, 1 }
+    for chordIndex,chord := range musicChords {
+        chordStart := float64(chordIndex)*chordLength
+        bass := semitoneFrequency(chord[0] - 12)
+        buffer.addTone(Tone {
+            waveform: waveSine,
+            start: chordStart,
+            duration: chordLength,
+            fromFrequency: bass,
+            toFrequency: bass,
+            volume: 0.3,
+            decay: 0.8,
+        })
+        for noteIndex,chordNote := range arpeggio {
+            // NOTE: Every other note goes up an octave, to keep the arpeggio from droning
+            semitones := chord[chordNote] + 12
+            if noteIndex%2 == 1 {
+                semitones += 12
+            }
+            frequency := semitoneFrequency(semitones)
+            buffer.addTone(Tone {
+                waveform: waveTriangle,
+                start: chordStart + float64(noteIndex)*musicNoteLength,
+                duration: musicNoteLength,
+                fromFrequency: frequency,
+                toFrequency: frequency,
+                volume: 0.15,
+                decay: 6.0,
+            })
+        }
+    }
+    return buffer.PCM()
+}
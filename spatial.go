@@ -0,0 +1,129 @@
+package main
+
+import "math"
+
+// enemyGrid buckets the enemies into uniform square cells over world space, so that finding all the
+// enemies near a point only has to look at the few cells around it instead of every enemy.
+// NOTE: It gets rebuilt from scratch every tick, which is cheap next to the queries it saves and
+//       means we never have to worry about keeping it in sync as enemies move, spawn or die.
+//       The cells only cover the area that the enemies are actually in, and are stored as ranges
+//       of a single slice (sorted by cell) rather than one slice per cell, to keep rebuilds cheap.
+type enemyGrid struct {
+    cellSize float64
+    minCell gridCell
+    width int
+    height int
+
+    cellStarts []int // NOTE: The enemies in cell i are sorted[cellStarts[i]:cellStarts[i+1]]
+    sorted []*Enemy
+    cellIndices []int // NOTE: Scratch space for Rebuild, the cell that each enemy is in
+}
+
+func newEnemyGrid() *enemyGrid {
+    return &enemyGrid {
+        cellSize: defaultPathSegmentLength,
+    }
+}
+
+func (grid *enemyGrid) cellAt(position Vec2) gridCell {
+    return gridCell {
+        int(math.Floor(position.x/grid.cellSize)),
+        int(math.Floor(position.y/grid.cellSize)),
+    }
+}
+
+// Rebuild clears the grid and re-inserts all of the given enemies with the given cell size.
+func (grid *enemyGrid) Rebuild(enemies []*Enemy, cellSize float64) {
+    grid.cellSize = cellSize
+    grid.width = 0
+    grid.height = 0
+    for i := range grid.sorted {
+        grid.sorted[i] = nil
+    }
+    grid.sorted = grid.sorted[:0]
+    if len(enemies) == 0 {
+        return
+    }
+
+    minCell := grid.cellAt(enemies[0].position)
+    maxCell := minCell
+    for _,enemy := range enemies[1:] {
+        cell := grid.cellAt(enemy.position)
+        minCell.x = minInt(minCell.x, cell.x)
+        minCell.y = minInt(minCell.y, cell.y)
+        maxCell.x = maxInt(maxCell.x, cell.x)
+        maxCell.y = maxInt(maxCell.y, cell.y)
+    }
+    grid.minCell = minCell
+    grid.width = maxCell.x - minCell.x + 1
+    grid.height = maxCell.y - minCell.y + 1
+
+    cellCount := grid.width*grid.height
+    if cap(grid.cellStarts) < cellCount+1 {
+        grid.cellStarts = make([]int, cellCount+1)
+    }
+    grid.cellStarts = grid.cellStarts[:cellCount+1]
+    for i := range grid.cellStarts {
+        grid.cellStarts[i] = 0
+    }
+
+    // NOTE: A counting sort, which keeps the enemies within each cell in the order they were given
+    grid.cellIndices = grid.cellIndices[:0]
+    for _,enemy := range enemies {
+        cell := grid.cellAt(enemy.position)
+        index := (cell.y-minCell.y)*grid.width + (cell.x-minCell.x)
+        grid.cellIndices = append(grid.cellIndices, index)
+        grid.cellStarts[index+1]++
+    }
+    for i := 1; i <= cellCount; i++ {
+        grid.cellStarts[i] += grid.cellStarts[i-1]
+    }
+    grid.sorted = append(grid.sorted, enemies...)
+    for index,enemy := range enemies {
+        cellIndex := grid.cellIndices[index]
+        grid.sorted[grid.cellStarts[cellIndex]] = enemy
+        grid.cellStarts[cellIndex]++
+    }
+    // NOTE: Each start has now been pushed along to where the next cell starts, so shift them back
+    copy(grid.cellStarts[1:], grid.cellStarts[:cellCount])
+    grid.cellStarts[0] = 0
+}
+
+// Query appends every enemy strictly within the given radius of the given point to the result slice
+// and returns it.
+// NOTE: The order is deterministic (cells row by row, then in the order the enemies were inserted)
+//       since targeting ties get broken by whichever enemy comes first, and replays depend on that.
+func (grid *enemyGrid) Query(center Vec2, radius float64, result []*Enemy) []*Enemy {
+    minCell := grid.cellAt(Vec2 { center.x-radius, center.y-radius })
+    maxCell := grid.cellAt(Vec2 { center.x+radius, center.y+radius })
+    minX := maxInt(minCell.x-grid.minCell.x, 0)
+    minY := maxInt(minCell.y-grid.minCell.y, 0)
+    maxX := minInt(maxCell.x-grid.minCell.x, grid.width-1)
+    maxY := minInt(maxCell.y-grid.minCell.y, grid.height-1)
+    if minX > maxX {
+        return result
+    }
+    for y := minY; y <= maxY; y++ {
+        rowStart := y*grid.width
+        for _,enemy := range grid.sorted[grid.cellStarts[rowStart+minX]:grid.cellStarts[rowStart+maxX+1]] {
+            if enemy.position.Sub(center).Magnitude() < radius {
+                result = append(result, enemy)
+            }
+        }
+    }
+    return result
+}
+
+func minInt(a, b int) int {
+    if a < b {
+        return a
+    }
+    return b
+}
+
+func maxInt(a, b int) int {
+    if a > b {
+        return a
+    }
+    return b
+}
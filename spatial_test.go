@@ -0,0 +1,95 @@
+package main
+
+import (
+    "math"
+    "sort"
+    "testing"
+)
+
+// bruteForceQuery is what enemyGrid.Query should return: every enemy strictly within the radius,
+// ordered by cell (row by row) and then by the order they were given in.
+func bruteForceQuery(grid *enemyGrid, enemies []*Enemy, center Vec2, radius float64) []*Enemy {
+    var result []*Enemy
+    for _,enemy := range enemies {
+        if enemy.position.Sub(center).Magnitude() < radius {
+            result = append(result, enemy)
+        }
+    }
+    sort.SliceStable(result, func(i, j int) bool {
+        a := grid.cellAt(result[i].position)
+        b := grid.cellAt(result[j].position)
+        if a.y != b.y {
+            return a.y < b.y
+        }
+        return a.x < b.x
+    })
+    return result
+}
+
+func TestEnemyGridMatchesBruteForce(t *testing.T) {
+    rng := newRNG(1)
+    grid := newEnemyGrid()
+    var found []*Enemy
+    for layout := 0; layout < 200; layout++ {
+        cellSize := 5.0 + 40.0*rng.Float64()
+        extent := 50.0 + 250.0*rng.Float64()
+        // NOTE: Some of the enemies sit exactly on the edges and corners of cells, and a few share a spot
+        var enemies []*Enemy
+        for i := rng.Intn(150); i >= 0; i-- {
+            position := Vec2 { extent*(2.0*rng.Float64() - 1.0), extent*(2.0*rng.Float64() - 1.0) }
+            switch rng.Intn(4) {
+            case 0:
+                position.x = cellSize*math.Floor(position.x/cellSize)
+            case 1:
+                position.x = cellSize*math.Floor(position.x/cellSize)
+                position.y = cellSize*math.Floor(position.y/cellSize)
+            case 2:
+                if len(enemies) > 0 {
+                    position = enemies[rng.Intn(len(enemies))].position
+                }
+            }
+            enemies = append(enemies, &Enemy { position: position })
+        }
+        grid.Rebuild(enemies, cellSize)
+
+        for query := 0; query < 50; query++ {
+            center := Vec2 { 1.2*extent*(2.0*rng.Float64() - 1.0), 1.2*extent*(2.0*rng.Float64() - 1.0) }
+            radius := 3.0*cellSize*rng.Float64()
+            switch rng.Intn(4) {
+            case 0:
+                // NOTE: A query circle whose edge lands exactly on a cell boundary
+                center.x = cellSize*math.Floor(center.x/cellSize) + 0.5*cellSize
+                radius = cellSize*float64(rng.Intn(3)) + 0.5*cellSize
+            case 1:
+                // NOTE: A query circle whose edge passes exactly through an enemy
+                if len(enemies) > 0 {
+                    radius = enemies[rng.Intn(len(enemies))].position.Sub(center).Magnitude()
+                }
+            case 2:
+                radius = 0.0
+            }
+
+            expected := bruteForceQuery(grid, enemies, center, radius)
+            found = grid.Query(center, radius, found[:0])
+            if len(found) != len(expected) {
+                t.Fatalf("layout %d (cell size %g): querying %g around %v found %d enemies, but there are %d",
+                         layout, cellSize, radius, center, len(found), len(expected))
+            }
+            for index := range expected {
+                if found[index] != expected[index] {
+                    t.Fatalf("layout %d (cell size %g): querying %g around %v found enemy %v at index %d, expected %v",
+                             layout, cellSize, radius, center, found[index].position, index, expected[index].position)
+                }
+            }
+        }
+    }
+}
+
+func TestEnemyGridEmpty(t *testing.T) {
+    grid := newEnemyGrid()
+    grid.Rebuild([]*Enemy { { position: Vec2 { 10.0, 10.0 } } }, 25.0)
+    grid.Rebuild(nil, 25.0)
+    if found := grid.Query(Vec2 { 10.0, 10.0 }, 100.0, nil); len(found) != 0 {
+        t.Errorf("an empty grid found %d enemies", len(found))
+    }
+}
@@ -0,0 +1,200 @@
+package main
+
+import "math"
+
+type StatusEffectKind int
+
+const (
+    effectSlow StatusEffectKind = iota // NOTE: Magnitude is the fraction of its speed that the enemy keeps
+    effectBurn // NOTE: Magnitude is damage per second, which ignores armour
+    effectPoison // NOTE: Magnitude is damage per second, which ignores armour
+    effectStun // NOTE: The enemy doesn't move at all, magnitude is unused
+    effectArmourShred // NOTE: Magnitude is how much armour the enemy loses
+
+    effectKindCount
+)
+
+type StackingRule int
+
+const (
+    stackStrongest StackingRule = iota // NOTE: Only the strongest application counts, for the longest of the durations
+    stackRefresh // NOTE: Each new application replaces the previous one
+    stackIndependent // NOTE: Each application is its own stack with its own duration, up to maxStacks of them
+)
+
+// StatusEffectType describes how one kind of status effect behaves.
+type StatusEffectType struct {
+    name string
+    stacking StackingRule
+    maxStacks int
+    strongerIsLower bool // NOTE: For slows, where a smaller speed multiplier is the stronger effect
+    tint [3]float64 // NOTE: Multiplied into the colour of every enemy that has this effect
+}
+
+var statusEffectTypes = [effectKindCount]StatusEffectType {
+    effectSlow: {
+        name: "Slow",
+        stacking: stackStrongest,
+        strongerIsLower: true,
+        tint: [3]float64 { 0.55, 0.8, 1.0 },
+    },
+    effectBurn: {
+        name: "Burn",
+        stacking: stackRefresh,
+        tint: [3]float64 { 1.0, 0.55, 0.35 },
+    },
+    effectPoison: {
+        name: "Poison",
+        stacking: stackIndependent,
+        maxStacks: 5,
+        tint: [3]float64 { 0.6, 1.0, 0.45 },
+    },
+    effectStun: {
+        name: "Stun",
+        stacking: stackStrongest,
+        tint: [3]float64 { 1.0, 1.0, 0.45 },
+    },
+    effectArmourShred: {
+        name: "Armour shred",
+        stacking: stackIndependent,
+        maxStacks: 3,
+        tint: [3]float64 { 0.85, 0.7, 0.6 },
+    },
+}
+
+// StatusEffect is a single timed effect (or a single stack of one) on an enemy.
+type StatusEffect struct {
+    kind StatusEffectKind
+    magnitude float64
+    timeRemaining float64
+    damageOwed float64 // NOTE: Damage over time builds up here until there's at least a whole point of it
+}
+
+func (s *StatusEffect) Type() *StatusEffectType {
+    return &statusEffectTypes[s.kind]
+}
+
+// EffectApplication is a status effect that a tower's projectiles apply to every enemy that they hit.
+type EffectApplication struct {
+    kind StatusEffectKind
+    magnitude float64
+    duration float64
+}
+
+// ApplyEffect gives the enemy a status effect, following that effect's stacking rule.
+func (e *Enemy) ApplyEffect(kind StatusEffectKind, magnitude, duration float64) {
+    effectType := &statusEffectTypes[kind]
+    newEffect := StatusEffect { kind: kind, magnitude: magnitude, timeRemaining: duration }
+
+    stacks := 0
+    weakest := -1
+    for index := range e.effects {
+        existing := &e.effects[index]
+        if existing.kind != kind {
+            continue
+        }
+        switch effectType.stacking {
+        case stackStrongest:
+            existingIsStronger := existing.magnitude > magnitude
+            if effectType.strongerIsLower {
+                existingIsStronger = existing.magnitude < magnitude
+            }
+            if !existingIsStronger {
+                existing.magnitude = magnitude
+            }
+            existing.timeRemaining = math.Max(existing.timeRemaining, duration)
+            return
+        case stackRefresh:
+            existing.magnitude = magnitude
+            existing.timeRemaining = duration
+            return
+        case stackIndependent:
+            stacks++
+            if (weakest < 0) || (existing.timeRemaining < e.effects[weakest].timeRemaining) {
+                weakest = index
+            }
+        }
+    }
+
+    // NOTE: Once an effect is at its maximum stacks, the stack that would run out first gets replaced
+    if (effectType.stacking == stackIndependent) && (stacks >= effectType.maxStacks) {
+        newEffect.damageOwed = e.effects[weakest].damageOwed
+        e.effects[weakest] = newEffect
+        return
+    }
+    e.effects = append(e.effects, newEffect)
+}
+
+func (e *Enemy) HasEffect(kind StatusEffectKind) bool {
+    for index := range e.effects {
+        if e.effects[index].kind == kind {
+            return true
+        }
+    }
+    return false
+}
+
+// speedMultiplier returns how much the enemy's status effects currently scale its speed by.
+func (e *Enemy) speedMultiplier() float64 {
+    result := 1.0
+    for index := range e.effects {
+        effect := &e.effects[index]
+        switch effect.kind {
+        case effectStun:
+            return 0.0
+        case effectSlow:
+            result *= effect.magnitude
+        }
+    }
+    return result
+}
+
+// Armour returns the enemy's armour after any shredding.
+func (e *Enemy) Armour() int {
+    shred := 0.0
+    for index := range e.effects {
+        if e.effects[index].kind == effectArmourShred {
+            shred += e.effects[index].magnitude
+        }
+    }
+    return int(math.Max(0.0, float64(e.armour) - shred))
+}
+
+// NOTE: Any damage this close to the next whole point counts as having got there
+const effectDamageTolerance = 1e-6
+
+// updateEffects applies any damage over time and removes the effects that have run out.
+func (e *Enemy) updateEffects() {
+    remaining := e.effects[:0]
+    for _,effect := range e.effects {
+        if (effect.kind == effectBurn) || (effect.kind == effectPoison) {
+            effect.damageOwed += effect.magnitude*math.Min(deltaTime, effect.timeRemaining)
+            // NOTE: Adding up a tick at a time comes out a hair short of the whole number it should be
+            wholeDamage := math.Floor(effect.damageOwed + effectDamageTolerance)
+            e.health -= int(wholeDamage)
+            effect.damageOwed -= wholeDamage
+        }
+        effect.timeRemaining -= deltaTime
+        if effect.timeRemaining > 0.0 {
+            remaining = append(remaining, effect)
+        }
+    }
+    e.effects = remaining
+}
+
+// EffectTint returns the colour that the enemy's status effects tint it with.
+func (e *Enemy) EffectTint() [3]float64 {
+    result := [3]float64 { 1.0, 1.0, 1.0 }
+    var tinted [effectKindCount]bool
+    for index := range e.effects {
+        kind := e.effects[index].kind
+        if tinted[kind] {
+            continue
+        }
+        tinted[kind] = true
+        for channel := range result {
+            result[channel] *= statusEffectTypes[kind].tint[channel]
+        }
+    }
+    return result
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+    "math"
+    "testing"
+)
+
+// applied is one application of a status effect, for setting up the tests.
+type applied struct {
+    magnitude float64
+    duration float64
+}
+
+// NOTE: Each case applies two overlapping effects of the same kind, one straight after the other
+func TestEffectStacking(t *testing.T) {
+    tests := []struct {
+        name string
+        kind StatusEffectKind
+        first applied
+        second applied
+        stacks int
+        magnitude float64 // NOTE: Of the first stack, which is the only one unless they stack independently
+        timeRemaining float64
+    } {
+        { "stronger slow", effectSlow, applied { 0.8, 1.0 }, applied { 0.5, 0.5 }, 1, 0.5, 1.0 },
+        { "weaker slow", effectSlow, applied { 0.5, 0.5 }, applied { 0.8, 2.0 }, 1, 0.5, 2.0 },
+        { "stun", effectStun, applied { 0.0, 1.0 }, applied { 0.0, 0.5 }, 1, 0.0, 1.0 },
+        { "weaker burn", effectBurn, applied { 3.0, 2.0 }, applied { 1.0, 1.0 }, 1, 1.0, 1.0 },
+        { "stronger burn", effectBurn, applied { 1.0, 1.0 }, applied { 3.0, 2.0 }, 1, 3.0, 2.0 },
+        { "poison", effectPoison, applied { 1.0, 2.0 }, applied { 2.0, 1.0 }, 2, 1.0, 2.0 },
+        { "armour shred", effectArmourShred, applied { 1.0, 3.0 }, applied { 1.0, 3.0 }, 2, 1.0, 3.0 },
+    }
+    for _,test := range tests {
+        enemy := &Enemy {}
+        enemy.ApplyEffect(test.kind, test.first.magnitude, test.first.duration)
+        enemy.ApplyEffect(test.kind, test.second.magnitude, test.second.duration)
+        if len(enemy.effects) != test.stacks {
+            t.Errorf("%s: expected %d stacks, but got %+v", test.name, test.stacks, enemy.effects)
+            continue
+        }
+        effect := enemy.effects[0]
+        if (effect.magnitude != test.magnitude) || (effect.timeRemaining != test.timeRemaining) {
+            t.Errorf("%s: expected a magnitude of %g for %gs, but got %g for %gs",
+                     test.name, test.magnitude, test.timeRemaining, effect.magnitude, effect.timeRemaining)
+        }
+    }
+}
+
+func TestIndependentStacksAreCapped(t *testing.T) {
+    enemy := &Enemy {}
+    maxStacks := statusEffectTypes[effectPoison].maxStacks
+    for stack := 0; stack < maxStacks; stack++ {
+        enemy.ApplyEffect(effectPoison, 1.0, 1.0 + float64(stack))
+    }
+    // NOTE: The new stack should replace the one with the least time left, rather than being added
+    enemy.ApplyEffect(effectPoison, 5.0, 10.0)
+    if len(enemy.effects) != maxStacks {
+        t.Fatalf("expected poison to be capped at %d stacks, but got %d", maxStacks, len(enemy.effects))
+    }
+    for _,effect := range enemy.effects {
+        if effect.timeRemaining == 1.0 {
+            t.Errorf("the stack that was closest to running out wasn't the one replaced: %+v", enemy.effects)
+        }
+    }
+}
+
+func TestEffectsChangeSpeedAndArmour(t *testing.T) {
+    enemy := &Enemy { speed: 10.0, armour: 3 }
+    enemy.ApplyEffect(effectSlow, 0.5, 1.0)
+    if speed := enemy.CurrentSpeed(); speed != 5.0 {
+        t.Errorf("a slow of 0.5 left the enemy moving at %g instead of 5", speed)
+    }
+    enemy.ApplyEffect(effectArmourShred, 1.0, 1.0)
+    enemy.ApplyEffect(effectArmourShred, 1.0, 1.0)
+    if armour := enemy.Armour(); armour != 1 {
+        t.Errorf("two armour shreds left the enemy with %d armour instead of 1", armour)
+    }
+    enemy.ApplyEffect(effectStun, 0.0, 0.5)
+    if speed := enemy.CurrentSpeed(); speed != 0.0 {
+        t.Errorf("a stunned enemy is still moving at %g", speed)
+    }
+
+    // NOTE: Once the stun wears off (counting down a tick at a time, it can take one more), the longer slow is still there
+    for tick := 0; tick <= int(math.Ceil(0.5/deltaTime)); tick++ {
+        enemy.updateEffects()
+    }
+    if speed := enemy.CurrentSpeed(); speed != 5.0 {
+        t.Errorf("after the stun wore off, the enemy should be back to its slowed speed of 5, but it's moving at %g", speed)
+    }
+}
+
+func TestDamageOverTime(t *testing.T) {
+    tests := []struct {
+        name string
+        kind StatusEffectKind
+        applications []applied
+        seconds float64
+        damage int
+    } {
+        { "burn", effectBurn, []applied { { 2.0, 3.0 } }, 1.0, 2 },
+        { "burn running out", effectBurn, []applied { { 2.0, 1.5 } }, 3.0, 3 },
+        { "refreshed burn", effectBurn, []applied { { 4.0, 3.0 }, { 2.0, 3.0 } }, 1.0, 2 },
+        { "poison stacks", effectPoison, []applied { { 2.0, 3.0 }, { 1.0, 3.0 } }, 2.0, 6 },
+        { "slow", effectSlow, []applied { { 0.5, 3.0 } }, 2.0, 0 },
+    }
+    for _,test := range tests {
+        enemy := &Enemy { health: 100, armour: 10 }
+        for _,application := range test.applications {
+            enemy.ApplyEffect(test.kind, application.magnitude, application.duration)
+        }
+        for tick := 0; tick < int(math.Floor(test.seconds/deltaTime + 0.5)); tick++ {
+            enemy.updateEffects()
+        }
+        // NOTE: Damage over time ignores armour, and builds up a fraction at a time so none of it is lost
+        if damage := 100-enemy.health; damage != test.damage {
+            t.Errorf("%s: expected %d damage after %gs, but got %d", test.name, test.damage, test.seconds, damage)
+        }
+    }
+}
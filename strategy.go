@@ -0,0 +1,150 @@
+// +build headless
+
+package main
+
+import (
+    "fmt"
+    "math/rand"
+    "sort"
+    "strings"
+)
+
+// Strategy decides what a simulated player does. It only gets to act through the same Input that a
+// human player's keyboard and mouse produce, so it can't do anything that a human couldn't.
+type Strategy interface {
+    // NextInput is called every tick with the current state of the game, and returns the input
+    // for that tick.
+    NextInput(g *GameState) Input
+}
+
+var strategyConstructors = map[string]func(seed int64) Strategy {
+    "none": func(seed int64) Strategy { return &noneStrategy {} },
+    "path": func(seed int64) Strategy { return &pathStrategy {} },
+    "random": func(seed int64) Strategy { return newRandomStrategy(seed) },
+    "corners": func(seed int64) Strategy { return newCornersBot() },
+    "coverage": func(seed int64) Strategy { return newCoverageBot() },
+    "hoard": func(seed int64) Strategy { return newHoardingBot() },
+}
+
+func strategyNames() []string {
+    var result []string
+    for name := range strategyConstructors {
+        result = append(result, name)
+    }
+    sort.Strings(result)
+    return result
+}
+
+func newStrategy(name string, seed int64) (Strategy, error) {
+    constructor, ok := strategyConstructors[name]
+    if !ok {
+        return nil, fmt.Errorf("unknown strategy %q, expected one of: %s", name, strings.Join(strategyNames(), ", "))
+    }
+    return constructor(seed), nil
+}
+
+// canBuild reports whether a tower of the given type could be placed at the given location right now.
+func canBuild(g *GameState, towerType int, loc Vec2) bool {
+    if !g.towerAllowed(towerType) || (g.credits < g.towerCosts[towerType]) {
+        return false
+    }
+    return g.checkPlacementSite(loc) == placementOK
+}
+
+// buildInput returns the input that works towards placing a tower of the given type at the given
+// location. Just like a human, this takes more than one tick if a different tower is selected.
+func buildInput(g *GameState, towerType int, loc Vec2) Input {
+    if !g.ghostTowerVisible {
+        return Input { toggleGhost: true }
+    }
+    if g.ghostTower.towerType != towerType {
+        return Input { selectTower: towerType+1 }
+    }
+    return Input { click: true, cursorLoc: loc }
+}
+
+// pathSideSpots returns the spots right beside the middle of each path segment, on both sides, in
+// order from the start of the path.
+func pathSideSpots(g *GameState) []Vec2 {
+    var result []Vec2
+    for i := 1; i < len(g.waypoints); i++ {
+        from := g.waypoints[i-1]
+        to := g.waypoints[i]
+        midpoint := from.Add(to).Mul(0.5)
+        side := Vec2 { -(to.y-from.y), to.x-from.x }.Normalized().Mul(0.5*g.params.PathSegmentLength)
+        result = append(result, midpoint.Add(side), midpoint.Sub(side))
+    }
+    return result
+}
+
+// noneStrategy never builds anything, as a baseline for how far the enemies get on their own.
+type noneStrategy struct {}
+
+func (s *noneStrategy) NextInput(g *GameState) Input {
+    return Input { startWave: true }
+}
+
+// pathStrategy builds as many basic towers as it can afford in between waves, beside the path
+// starting from where the enemies come in.
+type pathStrategy struct {
+    nextSpot int // NOTE: Spots only ever get taken, so there's no point checking the earlier ones again
+}
+
+func (s *pathStrategy) NextInput(g *GameState) Input {
+    if !g.canStartWave() {
+        return Input {}
+    }
+    spots := pathSideSpots(g)
+    if g.credits >= g.towerCosts[0] {
+        for ; s.nextSpot < len(spots); s.nextSpot++ {
+            if canBuild(g, 0, spots[s.nextSpot]) {
+                return buildInput(g, 0, spots[s.nextSpot])
+            }
+        }
+    }
+    return Input { startWave: true }
+}
+
+// randomStrategy builds random (affordable) towers at random spots around the path in between waves.
+type randomStrategy struct {
+    rng *rand.Rand
+
+    // NOTE: Building can take a few ticks, so we have to stick with the spot we picked until it's done
+    planned bool
+    plannedType int
+    plannedLoc Vec2
+}
+
+const randomStrategyAttempts = 100
+
+func newRandomStrategy(seed int64) *randomStrategy {
+    return &randomStrategy {
+        rng: rand.New(rand.NewSource(seed)),
+    }
+}
+
+func (s *randomStrategy) NextInput(g *GameState) Input {
+    if !g.canStartWave() {
+        return Input {}
+    }
+    if s.planned && canBuild(g, s.plannedType, s.plannedLoc) {
+        return buildInput(g, s.plannedType, s.plannedLoc)
+    }
+    s.planned = false
+
+    bounds := g.pathBoundingBox
+    for attempt := 0; attempt < randomStrategyAttempts; attempt++ {
+        towerType := s.rng.Intn(len(towerTypes))
+        loc := Vec2 {
+            bounds.MinX() + s.rng.Float64()*bounds.size.x,
+            bounds.MinY() + s.rng.Float64()*bounds.size.y,
+        }
+        if canBuild(g, towerType, loc) {
+            s.planned = true
+            s.plannedType = towerType
+            s.plannedLoc = loc
+            return buildInput(g, towerType, loc)
+        }
+    }
+    return Input { startWave: true }
+}
@@ -0,0 +1,160 @@
+package main
+
+import "strings"
+
+// ProjectileKind is how a tower's projectiles fly and what they hit.
+type ProjectileKind int
+
+const (
+    projectileHoming ProjectileKind = iota // NOTE: Chases its target, and finds a new one if its target dies first
+    projectileBallistic // NOTE: Flies straight at where its target will be, and hits the first enemy in its way
+    projectilePiercing // NOTE: Like ballistic, but carries on through up to pierceCount enemies
+    projectileArea // NOTE: Flies straight to where its target will be, and damages everything within splashRadius
+)
+
+// TowerType describes how one kind of tower behaves. Tower and Projectile just refer back into the
+// towerTypes catalog by index, so adding a new kind of tower only requires adding an entry here.
+type TowerType struct {
+    name string
+
+    cost int
+    costGrowth float64 // NOTE: The cost is multiplied by this at the start of every wave
+
+    attackRange float64 // NOTE: This gets scaled by the tower's scale, same as its size
+    cooldown float64
+    damage int
+
+    projectileKind ProjectileKind
+    projectileSpeedScale float64
+    projectileScale float64
+    pierceCount int
+    splashRadius float64
+    effects []EffectApplication
+
+    // NOTE: All the towers share the same animation frames, so we tell them apart by tinting them
+    tint [3]float64
+}
+
+var towerTypes = []TowerType {
+    {
+        name: "Basic",
+        cost: 2,
+        costGrowth: 1.5,
+        attackRange: towerAttackRange,
+        cooldown: 1.5,
+        damage: 1,
+        projectileSpeedScale: 1.0,
+        projectileScale: 1.0,
+        tint: [3]float64 { 1.0, 1.0, 1.0 },
+    },
+    {
+        name: "Splash",
+        cost: 4,
+        costGrowth: 1.6,
+        attackRange: 22.0,
+        cooldown: 2.0,
+        damage: 1,
+        projectileKind: projectileArea,
+        projectileSpeedScale: 0.75,
+        projectileScale: 1.5,
+        splashRadius: 10.0,
+        effects: []EffectApplication {
+            { kind: effectBurn, magnitude: 1.0, duration: 3.0 },
+        },
+        tint: [3]float64 { 1.0, 0.55, 0.45 },
+    },
+    {
+        name: "Frost",
+        cost: 3,
+        costGrowth: 1.5,
+        attackRange: towerAttackRange,
+        cooldown: 2.0,
+        damage: 1,
+        projectileSpeedScale: 1.0,
+        projectileScale: 1.0,
+        effects: []EffectApplication {
+            { kind: effectSlow, magnitude: 0.5, duration: 2.0 },
+        },
+        tint: [3]float64 { 0.55, 0.8, 1.0 },
+    },
+    {
+        name: "Rapid",
+        cost: 3,
+        costGrowth: 1.5,
+        attackRange: 20.0,
+        cooldown: 0.4,
+        damage: 1,
+        projectileKind: projectileBallistic,
+        projectileSpeedScale: 1.25,
+        projectileScale: 0.6,
+        effects: []EffectApplication {
+            { kind: effectArmourShred, magnitude: 1.0, duration: 3.0 },
+        },
+        tint: [3]float64 { 1.0, 0.9, 0.5 },
+    },
+    {
+        name: "Sniper",
+        cost: 6,
+        costGrowth: 1.7,
+        attackRange: 60.0,
+        cooldown: 3.0,
+        damage: 4,
+        projectileKind: projectilePiercing,
+        projectileSpeedScale: 2.0,
+        pierceCount: 3,
+        effects: []EffectApplication {
+            { kind: effectStun, duration: 0.5 },
+        },
+        projectileScale: 0.8,
+        tint: [3]float64 { 0.7, 1.0, 0.6 },
+    },
+    {
+        name: "Venom",
+        cost: 3,
+        costGrowth: 1.5,
+        attackRange: towerAttackRange,
+        cooldown: 1.0,
+        damage: 1,
+        projectileSpeedScale: 1.0,
+        projectileScale: 0.8,
+        effects: []EffectApplication {
+            { kind: effectPoison, magnitude: 0.5, duration: 4.0 },
+        },
+        tint: [3]float64 { 0.75, 0.55, 1.0 },
+    },
+}
+
+// TowerUpgrade is one tier of upgrades, the bonuses of each tier stack on top of all the previous ones.
+type TowerUpgrade struct {
+    costFactor float64 // NOTE: Relative to the current cost of a new tower of the same type
+    damageBonus int
+    rangeScale float64
+    cooldownScale float64
+}
+
+var towerUpgrades = []TowerUpgrade {
+    { costFactor: 1.0, damageBonus: 0, rangeScale: 1.15, cooldownScale: 0.85 },
+    { costFactor: 1.5, damageBonus: 1, rangeScale: 1.15, cooldownScale: 0.85 },
+    { costFactor: 2.0, damageBonus: 1, rangeScale: 1.2, cooldownScale: 0.75 },
+}
+
+// NOTE: Selling a tower refunds this fraction of everything that was spent on it, including upgrades
+const towerSellRefund = 0.6
+
+// towerTypeByName finds a tower type by its name, ignoring case so that "basic" finds "Basic".
+func towerTypeByName(name string) (int, bool) {
+    for index := range towerTypes {
+        if strings.EqualFold(towerTypes[index].name, name) {
+            return index, true
+        }
+    }
+    return 0, false
+}
+
+func towerTypeNames() []string {
+    var result []string
+    for _,towerType := range towerTypes {
+        result = append(result, strings.ToLower(towerType.name))
+    }
+    return result
+}
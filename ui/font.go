@@ -0,0 +1,88 @@
+package ui
+
+import (
+    "fmt"
+    "image"
+    "strings"
+
+    "github.com/hajimehoshi/ebiten"
+)
+
+// NOTE: The font atlas has every printable ASCII character, starting from space, laid out in rows
+const (
+    fontFirstChar = ' '
+    fontLastChar = '~'
+)
+
+// Font is a fixed-width bitmap font, where every character is the same size.
+type Font struct {
+    glyphs [fontLastChar-fontFirstChar+1]*ebiten.Image
+    GlyphWidth int
+    GlyphHeight int
+}
+
+// subImager is implemented by all of the standard library's image types.
+type subImager interface {
+    SubImage(r image.Rectangle) image.Image
+}
+
+// NewFont cuts the glyphs out of an atlas image, which should have them laid out left to right and
+// then top to bottom, in cells of the given size.
+func NewFont(atlas image.Image, glyphWidth, glyphHeight int) (*Font, error) {
+    source, ok := atlas.(subImager)
+    if !ok {
+        return nil, fmt.Errorf("unsupported font atlas image type %T", atlas)
+    }
+    bounds := atlas.Bounds()
+    columns := bounds.Dx()/glyphWidth
+    result := &Font { GlyphWidth: glyphWidth, GlyphHeight: glyphHeight }
+    for index := range result.glyphs {
+        x := bounds.Min.X + (index%columns)*glyphWidth
+        y := bounds.Min.Y + (index/columns)*glyphHeight
+        cell := image.Rect(x, y, x+glyphWidth, y+glyphHeight)
+        if !cell.In(bounds) {
+            return nil, fmt.Errorf("font atlas is too small for all of the characters")
+        }
+        glyph, err := ebiten.NewImageFromImage(source.SubImage(cell), ebiten.FilterNearest)
+        if err != nil {
+            return nil, err
+        }
+        result.glyphs[index] = glyph
+    }
+    return result, nil
+}
+
+// Measure returns the width and height of the given text, which can have multiple lines.
+func (f *Font) Measure(text string) (float64, float64) {
+    lines := strings.Split(text, "\n")
+    longest := 0
+    for _,line := range lines {
+        if len(line) > longest {
+            longest = len(line)
+        }
+    }
+    return float64(longest*f.GlyphWidth), float64(len(lines)*f.GlyphHeight)
+}
+
+// DrawText draws the given text with its top-left corner at the given position. Characters that
+// the font doesn't have are drawn as '?'.
+func (f *Font) DrawText(screen *ebiten.Image, text string, x, y float64, clr ebiten.ColorM) {
+    lineX := x
+    for _,char := range text {
+        if char == '\n' {
+            x = lineX
+            y += float64(f.GlyphHeight)
+            continue
+        }
+        if (char < fontFirstChar) || (char > fontLastChar) {
+            char = '?'
+        }
+        if char != ' ' {
+            opts := ebiten.DrawImageOptions{}
+            opts.GeoM.Translate(x, y)
+            opts.ColorM = clr
+            screen.DrawImage(f.glyphs[char-fontFirstChar], &opts)
+        }
+        x += float64(f.GlyphWidth)
+    }
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+    "github.com/hajimehoshi/ebiten"
+)
+
+// Root is the top of the widget tree. It draws everything, keeps track of which widget the mouse
+// is over and passes clicks on to it.
+type Root struct {
+    Theme Theme
+    Children []Widget
+
+    hovered Widget
+    cursorX float64
+    cursorY float64
+}
+
+// widgetAt returns the top-most visible widget under the given point, out of the given widgets and
+// all of their children.
+func widgetAt(widgets []Widget, x, y float64) Widget {
+    // NOTE: Later widgets get drawn over earlier ones, so they get the first chance to be hit
+    for index := len(widgets)-1; index >= 0; index-- {
+        widget := widgets[index]
+        if widget.IsHidden() || widget.IsMouseTransparent() || !widget.Bounds().Contains(x, y) {
+            continue
+        }
+        if parent, ok := widget.(container); ok {
+            if child := widgetAt(parent.children(), x, y); child != nil {
+                return child
+            }
+        }
+        return widget
+    }
+    return nil
+}
+
+// Update moves the mouse to the given screen position and clicks there if clicked is set. It
+// returns whether the mouse is over the UI, in which case the click shouldn't also go to whatever
+// is underneath the UI.
+func (r *Root) Update(cursorX, cursorY float64, clicked bool) bool {
+    r.cursorX = cursorX
+    r.cursorY = cursorY
+
+    hovered := widgetAt(r.Children, cursorX, cursorY)
+    if hovered != r.hovered {
+        if previous, ok := r.hovered.(clickable); ok {
+            previous.setHovered(false)
+        }
+        if current, ok := hovered.(clickable); ok {
+            current.setHovered(true)
+        }
+        r.hovered = hovered
+    }
+    if clicked {
+        if target, ok := hovered.(clickable); ok {
+            target.click()
+        }
+    }
+    return hovered != nil
+}
+
+func (r *Root) Draw(screen *ebiten.Image) {
+    for _,child := range r.Children {
+        if !child.IsHidden() {
+            child.Draw(screen, &r.Theme)
+        }
+    }
+
+    if r.hovered == nil || r.hovered.IsHidden() {
+        return
+    }
+    withTooltip, ok := r.hovered.(tooltipper)
+    if !ok || (withTooltip.tooltip() == "") {
+        return
+    }
+    text := withTooltip.tooltip()
+    width, height := r.Theme.Font.Measure(text)
+    screenWidth, screenHeight := screen.Size()
+    // NOTE: Keep the tooltip on the screen, flipping it to the other side of the cursor if need be
+    box := Rect { r.cursorX + 8, r.cursorY - height - 6, width + 6, height + 6 }
+    if box.X+box.W > float64(screenWidth) {
+        box.X = r.cursorX - box.W - 4
+    }
+    if box.Y < 0 {
+        box.Y = r.cursorY + 12
+    }
+    if box.Y+box.H > float64(screenHeight) {
+        box.Y = float64(screenHeight) - box.H
+    }
+    r.Theme.fillRect(screen, box, r.Theme.Tooltip)
+    r.Theme.strokeRect(screen, box, r.Theme.Border)
+    r.Theme.Font.DrawText(screen, text, box.X+3, box.Y+3, r.Theme.Text.ColorM())
+}
@@ -0,0 +1,225 @@
+// Package ui is a small retained-mode UI toolkit on top of ebiten: the widgets get built once and
+// then have their text and visibility updated every frame, and a Root draws them and works out
+// which one the mouse is over.
+package ui
+
+import (
+    "github.com/hajimehoshi/ebiten"
+)
+
+// Rect is an area of the screen in pixels, from its top-left corner.
+type Rect struct {
+    X, Y, W, H float64
+}
+
+func NewRect(x, y, w, h float64) Rect {
+    return Rect { x, y, w, h }
+}
+
+func (r Rect) Contains(x, y float64) bool {
+    return (x >= r.X) && (x < r.X+r.W) && (y >= r.Y) && (y < r.Y+r.H)
+}
+
+// Color is a straight (not premultiplied) RGBA colour, with every channel from 0 to 1.
+type Color struct {
+    R, G, B, A float64
+}
+
+func RGBA(r, g, b, a float64) Color {
+    return Color { r, g, b, a }
+}
+
+func (c Color) ColorM() ebiten.ColorM {
+    return ebiten.ScaleColor(c.R, c.G, c.B, c.A)
+}
+
+// Theme is everything that the widgets need to draw themselves that isn't specific to one of them.
+type Theme struct {
+    Font *Font
+    Pixel *ebiten.Image // NOTE: A single white pixel, which gets scaled up to draw solid rectangles
+    Text Color
+    DisabledText Color
+    Panel Color
+    Button Color
+    ButtonHovered Color
+    ButtonSelected Color
+    Border Color
+    Tooltip Color
+}
+
+func (t *Theme) fillRect(screen *ebiten.Image, r Rect, clr Color) {
+    if clr.A <= 0.0 {
+        return
+    }
+    opts := ebiten.DrawImageOptions{}
+    opts.GeoM.Scale(r.W, r.H)
+    opts.GeoM.Translate(r.X, r.Y)
+    opts.ColorM = clr.ColorM()
+    screen.DrawImage(t.Pixel, &opts)
+}
+
+func (t *Theme) strokeRect(screen *ebiten.Image, r Rect, clr Color) {
+    t.fillRect(screen, Rect { r.X, r.Y, r.W, 1 }, clr)
+    t.fillRect(screen, Rect { r.X, r.Y+r.H-1, r.W, 1 }, clr)
+    t.fillRect(screen, Rect { r.X, r.Y, 1, r.H }, clr)
+    t.fillRect(screen, Rect { r.X+r.W-1, r.Y, 1, r.H }, clr)
+}
+
+// Widget is anything that can be placed in the UI.
+type Widget interface {
+    Bounds() Rect
+    IsHidden() bool
+    IsMouseTransparent() bool
+    Draw(screen *ebiten.Image, theme *Theme)
+}
+
+// Base holds the parts that every widget has, and gets embedded in all of them.
+type Base struct {
+    Rect Rect
+    Hidden bool
+    Tooltip string // NOTE: Shown next to the cursor while it's over the widget, if it isn't empty
+    MouseTransparent bool // NOTE: The mouse goes straight through these, to whatever is underneath
+}
+
+func (b *Base) Bounds() Rect {
+    return b.Rect
+}
+
+func (b *Base) IsHidden() bool {
+    return b.Hidden
+}
+
+func (b *Base) IsMouseTransparent() bool {
+    return b.MouseTransparent
+}
+
+func (b *Base) tooltip() string {
+    return b.Tooltip
+}
+
+type tooltipper interface {
+    tooltip() string
+}
+
+type clickable interface {
+    click()
+    setHovered(hovered bool)
+}
+
+type container interface {
+    children() []Widget
+}
+
+// Panel is a filled rectangle that groups other widgets together. Their positions are absolute,
+// not relative to the panel.
+type Panel struct {
+    Base
+    Background Color // NOTE: Uses the theme's panel colour if this is left transparent
+    Children []Widget
+}
+
+func (p *Panel) children() []Widget {
+    return p.Children
+}
+
+func (p *Panel) Draw(screen *ebiten.Image, theme *Theme) {
+    background := p.Background
+    if background.A <= 0.0 {
+        background = theme.Panel
+    }
+    theme.fillRect(screen, p.Rect, background)
+    theme.strokeRect(screen, p.Rect, theme.Border)
+    for _,child := range p.Children {
+        if !child.IsHidden() {
+            child.Draw(screen, theme)
+        }
+    }
+}
+
+// Label is a piece of text, which can have multiple lines.
+type Label struct {
+    Base
+    Text string
+    Color Color // NOTE: Uses the theme's text colour if this is left transparent
+    AlignRight bool
+}
+
+func (l *Label) Draw(screen *ebiten.Image, theme *Theme) {
+    clr := l.Color
+    if clr.A <= 0.0 {
+        clr = theme.Text
+    }
+    x := l.Rect.X
+    if l.AlignRight {
+        width, _ := theme.Font.Measure(l.Text)
+        x = l.Rect.X + l.Rect.W - width
+    }
+    theme.Font.DrawText(screen, l.Text, x, l.Rect.Y, clr.ColorM())
+}
+
+// Icon is an image scaled to fit its rectangle.
+type Icon struct {
+    Base
+    Image *ebiten.Image
+    Tint ebiten.ColorM
+}
+
+func (i *Icon) Draw(screen *ebiten.Image, theme *Theme) {
+    drawIcon(screen, i.Image, i.Rect, i.Tint)
+}
+
+func drawIcon(screen *ebiten.Image, img *ebiten.Image, r Rect, tint ebiten.ColorM) {
+    width, height := img.Size()
+    opts := ebiten.DrawImageOptions{}
+    opts.GeoM.Scale(r.W/float64(width), r.H/float64(height))
+    opts.GeoM.Translate(r.X, r.Y)
+    opts.ColorM = tint
+    screen.DrawImage(img, &opts)
+}
+
+// Button is a clickable box with some text and optionally an icon to the left of it.
+type Button struct {
+    Base
+    Text string
+    Icon *ebiten.Image
+    IconTint ebiten.ColorM
+    Disabled bool
+    Selected bool
+    OnClick func()
+
+    hovered bool
+}
+
+func (b *Button) click() {
+    if !b.Disabled && (b.OnClick != nil) {
+        b.OnClick()
+    }
+}
+
+func (b *Button) setHovered(hovered bool) {
+    b.hovered = hovered
+}
+
+func (b *Button) Draw(screen *ebiten.Image, theme *Theme) {
+    background := theme.Button
+    if b.Selected {
+        background = theme.ButtonSelected
+    } else if b.hovered && !b.Disabled {
+        background = theme.ButtonHovered
+    }
+    theme.fillRect(screen, b.Rect, background)
+    theme.strokeRect(screen, b.Rect, theme.Border)
+
+    textX := b.Rect.X + 3
+    if b.Icon != nil {
+        iconSize := b.Rect.H - 4
+        drawIcon(screen, b.Icon, Rect { b.Rect.X+2, b.Rect.Y+2, iconSize, iconSize }, b.IconTint)
+        textX += iconSize + 1
+    }
+    textClr := theme.Text
+    if b.Disabled {
+        textClr = theme.DisabledText
+    }
+    textY := b.Rect.Y + 0.5*(b.Rect.H - float64(theme.Font.GlyphHeight))
+    theme.Font.DrawText(screen, b.Text, textX, textY, textClr.ColorM())
+}
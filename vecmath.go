@@ -46,6 +46,27 @@ func (v Vec2) Magnitude() float64 {
     return math.Sqrt(v.x*v.x + v.y*v.y)
 }
 
+// Lerp returns the point that is the given fraction of the way from v to u.
+func (v Vec2) Lerp(u Vec2, t float64) Vec2 {
+    return v.Add(u.Sub(v).Mul(t))
+}
+
+func (v Vec2) Dot(u Vec2) float64 {
+    return v.x*u.x + v.y*u.y
+}
+
+// DistanceToSegment returns the distance from v to the closest point on the line segment from a to b.
+func (v Vec2) DistanceToSegment(a, b Vec2) float64 {
+    segment := b.Sub(a)
+    segmentLengthSq := segment.Dot(segment)
+    if segmentLengthSq == 0.0 {
+        return v.Sub(a).Magnitude()
+    }
+    t := math.Max(0.0, math.Min(1.0, v.Sub(a).Dot(segment)/segmentLengthSq))
+    closest := a.Add(segment.Mul(t))
+    return v.Sub(closest).Magnitude()
+}
+
 func (v Vec2) Rotate90CCW() Vec2 {
     result := Vec2 {
         x: -v.y,
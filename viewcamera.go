@@ -0,0 +1,82 @@
+// +build !headless
+
+package main
+
+import "math"
+
+const (
+    cameraSmoothing = 6.0 // NOTE: Roughly how many times per second the view closes the gap to where it's heading
+    cameraPanSpeed = 0.75 // NOTE: Fractions of the view's width per second
+    cameraWheelZoom = 1.15 // NOTE: How much one notch of the mouse wheel zooms by
+    cameraMinZoom = 0.1 // NOTE: Relative to the size of the game's own camera, which fits the whole path
+    cameraMaxZoom = 2.0
+)
+
+// ViewCamera is the part of the world that actually gets drawn. The game's own camera only ever zooms
+// out to fit the whole path, while this one follows it around but also lets the player pan and zoom.
+// NOTE: This only exists on the rendering side, the simulation (and so replays) never see it.
+type ViewCamera struct {
+    view Rect
+    target Rect // NOTE: The view animates towards this
+    following bool // NOTE: Whether the target should follow the game's camera as the path grows
+    gameCamera Rect // NOTE: The game's camera as of the last update, so we can tell when it changes
+}
+
+func newViewCamera(gameCamera Rect) *ViewCamera {
+    return &ViewCamera {
+        view: gameCamera,
+        target: gameCamera,
+        following: true,
+        gameCamera: gameCamera,
+    }
+}
+
+// Update moves the view towards its target, which follows the game's camera unless the player has
+// taken control of the view themselves.
+func (c *ViewCamera) Update(gameCamera Rect, frameTime float64) {
+    if gameCamera != c.gameCamera {
+        // NOTE: The path only ever grows, so the game's camera getting smaller means we restarted
+        //       or loaded a game and there's nothing sensible to animate from
+        if gameCamera.size.x < c.gameCamera.size.x {
+            c.view = gameCamera
+            c.following = true
+        }
+        if c.following {
+            c.target = gameCamera
+        }
+        c.gameCamera = gameCamera
+    }
+
+    t := 1.0 - math.Exp(-cameraSmoothing*frameTime)
+    c.view.position = c.view.position.Add(c.target.position.Sub(c.view.position).Mul(t))
+    c.view.size = c.view.size.Add(c.target.size.Sub(c.view.size).Mul(t))
+}
+
+// Pan moves the view (and where it's heading) by the given offset in world space.
+func (c *ViewCamera) Pan(offset Vec2) {
+    c.view.position = c.view.position.Add(offset)
+    c.target.position = c.target.position.Add(offset)
+    c.following = false
+}
+
+// Zoom scales the size of the view that we're heading towards by the given factor, keeping the
+// given world location in the same place on the screen.
+func (c *ViewCamera) Zoom(factor float64, anchor Vec2) {
+    minSize := c.gameCamera.size.x*cameraMinZoom
+    maxSize := c.gameCamera.size.x*cameraMaxZoom
+    newWidth := math.Max(minSize, math.Min(maxSize, c.target.size.x*factor))
+    factor = newWidth/c.target.size.x
+
+    // NOTE: The anchor is a fixed fraction of the way across the view, so scaling the offset from it
+    //       to the view's centre keeps it there. Since the view lerps its position and size by the
+    //       same amount every frame, it also stays put while the view animates.
+    c.target.position = anchor.Add(c.target.position.Sub(anchor).Mul(factor))
+    c.target.size = c.target.size.Mul(factor)
+    c.following = false
+}
+
+// Fit heads back to showing the whole path, and follows it again as it grows.
+func (c *ViewCamera) Fit() {
+    c.target = c.gameCamera
+    c.following = true
+}
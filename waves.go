@@ -0,0 +1,215 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "math"
+)
+
+const waveSetVersion = 1
+
+// WaveGroup is a run of enemies of a single type that get sent one after the other.
+type WaveGroup struct {
+    Enemy string `json:"enemy"`
+    Count int `json:"count"`
+    Interval float64 `json:"interval"` // NOTE: Seconds in between each enemy in the group
+    Delay float64 `json:"delay"` // NOTE: Seconds from the end of the previous group to the start of this one
+}
+
+// WaveDefinition describes a single wave. The multipliers and increases are applied to the
+// enemy stats at the start of the wave, and carry over into all of the waves that follow it.
+type WaveDefinition struct {
+    Label string `json:"label,omitempty"`
+    Groups []WaveGroup `json:"groups"`
+    SpeedMultiplier float64 `json:"speedMultiplier"`
+    HealthMultiplier float64 `json:"healthMultiplier"`
+    HealthIncrease int `json:"healthIncrease,omitempty"`
+    BountyIncrease int `json:"bountyIncrease,omitempty"`
+}
+
+// WaveSet is the full progression of waves for a game.
+// NOTE: Once we run out of waves, they carry on growing the way that the original hard-coded waves
+//       did (see extrapolate), so the end of the file isn't the end of the game
+type WaveSet struct {
+    Version int `json:"version"`
+    Waves []WaveDefinition `json:"waves"`
+}
+
+// waveSpawn is a single entry in the queue of enemies to be sent during the current wave.
+type waveSpawn struct {
+    enemyType int
+    delay float64 // NOTE: Seconds from the previous spawn to this one
+}
+
+func enemyTypeByName(name string) (int, bool) {
+    for index := range enemyTypes {
+        if enemyTypes[index].name == name {
+            return index, true
+        }
+    }
+    return 0, false
+}
+
+func (w *WaveSet) Wave(wave int) *WaveDefinition {
+    if wave > len(w.Waves) {
+        return w.extrapolate(wave)
+    }
+    return &w.Waves[wave-1]
+}
+
+func (d *WaveDefinition) hasBoss() bool {
+    for _,group := range d.Groups {
+        if group.Enemy == enemyTypes[enemyBoss].name {
+            return true
+        }
+    }
+    return false
+}
+
+// waveSize is how many enemies the original hard-coded waves sent in the given wave (the wave
+// number more than the wave before), which the extrapolated waves keep growing in proportion to.
+func waveSize(wave int) float64 {
+    return float64(wave*(wave+1))/2.0
+}
+
+// scaleGroup grows a group from the given wave to fit in a later one, with more enemies sent
+// closer together so that the whole group takes the same amount of time.
+func scaleGroup(group WaveGroup, from, to int) WaveGroup {
+    scale := waveSize(to)/waveSize(from)
+    group.Count = int(math.Max(1.0, math.Floor(float64(group.Count)*scale + 0.5)))
+    group.Interval /= scale
+    group.Delay /= scale
+    return group
+}
+
+// extrapolate makes up a wave past the end of the set. The enemies come from the last wave without a
+// boss, scaled up to the size of the new wave, and every 5th wave also gets the bosses from the last
+// boss wave. Like the original waves, the enemies get more health on odd waves, and more bounty
+// every 4th wave.
+func (w *WaveSet) extrapolate(wave int) *WaveDefinition {
+    template := len(w.Waves)
+    for (template > 1) && w.Waves[template-1].hasBoss() {
+        template--
+    }
+    last := &w.Waves[template-1]
+    result := &WaveDefinition {
+        SpeedMultiplier: last.SpeedMultiplier,
+        HealthMultiplier: last.HealthMultiplier,
+    }
+    for _,group := range last.Groups {
+        result.Groups = append(result.Groups, scaleGroup(group, template, wave))
+    }
+
+    if wave%5 == 0 {
+        for bossWave := len(w.Waves); bossWave > 0; bossWave-- {
+            definition := &w.Waves[bossWave-1]
+            if !definition.hasBoss() {
+                continue
+            }
+            result.Label = definition.Label
+            for _,group := range definition.Groups {
+                if group.Enemy == enemyTypes[enemyBoss].name {
+                    // NOTE: Only the spacing changes, there's no need for more than one boss at a time
+                    bossGroup := scaleGroup(group, bossWave, wave)
+                    bossGroup.Count = group.Count
+                    result.Groups = append(result.Groups, bossGroup)
+                }
+            }
+            break
+        }
+    }
+
+    if wave%2 == 1 {
+        result.HealthIncrease = 1
+    }
+    if wave%4 == 1 {
+        result.BountyIncrease = 1
+    }
+    return result
+}
+
+// SpawnQueue returns all the enemies that should be sent during the given wave, in order.
+func (d *WaveDefinition) SpawnQueue() []waveSpawn {
+    var result []waveSpawn
+    for _,group := range d.Groups {
+        enemyType, _ := enemyTypeByName(group.Enemy)
+        for i := 0; i < group.Count; i++ {
+            delay := group.Interval
+            if i == 0 {
+                delay = group.Delay
+            }
+            result = append(result, waveSpawn { enemyType, delay })
+        }
+    }
+    return result
+}
+
+func (d *WaveDefinition) validate(wave int) error {
+    if len(d.Groups) == 0 {
+        return fmt.Errorf("wave %d has no groups of enemies", wave)
+    }
+    for index,group := range d.Groups {
+        if _,ok := enemyTypeByName(group.Enemy); !ok {
+            return fmt.Errorf("wave %d group %d has unknown enemy type %q", wave, index+1, group.Enemy)
+        }
+        if group.Count <= 0 {
+            return fmt.Errorf("wave %d group %d has a non-positive count %d", wave, index+1, group.Count)
+        }
+        if (group.Interval < 0.0) || (group.Delay < 0.0) {
+            return fmt.Errorf("wave %d group %d has a negative interval or delay", wave, index+1)
+        }
+    }
+    if (d.SpeedMultiplier <= 0.0) || (d.HealthMultiplier <= 0.0) {
+        return fmt.Errorf("wave %d has a non-positive speed or health multiplier", wave)
+    }
+    return nil
+}
+
+func (w *WaveSet) validate() error {
+    if w.Version != waveSetVersion {
+        return fmt.Errorf("version %d is not supported, only version %d is", w.Version, waveSetVersion)
+    }
+    if len(w.Waves) == 0 {
+        return fmt.Errorf("there are no waves defined")
+    }
+    for index := range w.Waves {
+        if err := w.Waves[index].validate(index+1); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func parseWaveSet(data []byte) (*WaveSet, error) {
+    result := &WaveSet{}
+    if err := json.Unmarshal(data, result); err != nil {
+        return nil, err
+    }
+    if err := result.validate(); err != nil {
+        return nil, err
+    }
+    return result, nil
+}
+
+func loadWaveSet(path string) (*WaveSet, error) {
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    result, err := parseWaveSet(data)
+    if err != nil {
+        return nil, fmt.Errorf("invalid wave file %s: %v", path, err)
+    }
+    return result, nil
+}
+
+// defaultWaveSet returns the waves that ship with the game.
+// NOTE: The data is compiled in with go-bindata, so if this fails then the build itself is broken
+func defaultWaveSet() *WaveSet {
+    result, err := parseWaveSet(MustAsset("_resources/waves.json"))
+    if err != nil {
+        panic("invalid default wave file: " + err.Error())
+    }
+    return result
+}
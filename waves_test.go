@@ -0,0 +1,92 @@
+package main
+
+import (
+    "math"
+    "strings"
+    "testing"
+)
+
+func TestParseWaveSetRejectsBadWaves(t *testing.T) {
+    const good = `{ "enemy": "Grunt", "count": 2, "interval": 1.0, "delay": 0.0 }`
+    tests := []struct {
+        name string
+        data string
+        err string
+    } {
+        { "bad json", `{ "version": 1, "waves": [`, "unexpected end" },
+        { "old version", `{ "version": 0, "waves": [] }`, "version 0 is not supported" },
+        { "no waves", `{ "version": 1, "waves": [] }`, "no waves" },
+        { "no groups", `{ "version": 1, "waves": [ { "groups": [], "speedMultiplier": 1.0, "healthMultiplier": 1.0 } ] }`, "wave 1 has no groups" },
+        { "unknown enemy", `{ "version": 1, "waves": [ { "groups": [ ` + good + `, { "enemy": "Dragon", "count": 1, "interval": 1.0, "delay": 0.0 } ], "speedMultiplier": 1.0, "healthMultiplier": 1.0 } ] }`, `wave 1 group 2 has unknown enemy type "Dragon"` },
+        { "no enemies", `{ "version": 1, "waves": [ { "groups": [ { "enemy": "Grunt", "count": 0, "interval": 1.0, "delay": 0.0 } ], "speedMultiplier": 1.0, "healthMultiplier": 1.0 } ] }`, "non-positive count" },
+        { "negative delay", `{ "version": 1, "waves": [ { "groups": [ { "enemy": "Grunt", "count": 1, "interval": 1.0, "delay": -1.0 } ], "speedMultiplier": 1.0, "healthMultiplier": 1.0 } ] }`, "negative interval or delay" },
+        { "no speed", `{ "version": 1, "waves": [ { "groups": [ ` + good + ` ], "speedMultiplier": 1.0, "healthMultiplier": 1.0 }, { "groups": [ ` + good + ` ], "healthMultiplier": 1.0 } ] }`, "wave 2 has a non-positive speed or health multiplier" },
+    }
+    for _,test := range tests {
+        if _,err := parseWaveSet([]byte(test.data)); (err == nil) || !strings.Contains(err.Error(), test.err) {
+            t.Errorf("%s: expected an error containing %q, but got %v", test.name, test.err, err)
+        }
+    }
+
+    waves, err := parseWaveSet([]byte(`{ "version": 1, "waves": [ { "groups": [ ` + good + ` ], "speedMultiplier": 1.5, "healthMultiplier": 1.0 } ] }`))
+    if err != nil {
+        t.Fatalf("a valid wave set failed to parse: %v", err)
+    }
+    if (len(waves.Waves) != 1) || (waves.Waves[0].SpeedMultiplier != 1.5) || (len(waves.Wave(1).SpawnQueue()) != 2) {
+        t.Errorf("the valid wave set came out wrong: %+v", waves.Waves)
+    }
+}
+
+// waveEnemies counts the bosses, and all of the other enemies, that get sent in a wave.
+func waveEnemies(definition *WaveDefinition) (int, int) {
+    bosses := 0
+    others := 0
+    for _,spawn := range definition.SpawnQueue() {
+        if spawn.enemyType == enemyBoss {
+            bosses++
+        } else {
+            others++
+        }
+    }
+    return bosses, others
+}
+
+func TestExtrapolatedWavesKeepGrowing(t *testing.T) {
+    waves := defaultWaveSet()
+    last := len(waves.Waves)
+    for wave := last+1; wave <= last+30; wave++ {
+        definition := waves.Wave(wave)
+        if err := definition.validate(wave); err != nil {
+            t.Fatalf("extrapolated %v", err)
+        }
+        // NOTE: Each group gets rounded on its own, so the total can be off by a little
+        bosses, others := waveEnemies(definition)
+        if math.Abs(float64(others) - waveSize(wave)) > float64(len(definition.Groups)) {
+            t.Errorf("wave %d has %d enemies, but the original waves would have sent %g", wave, others, waveSize(wave))
+        }
+        if (bosses > 0) != (wave%5 == 0) {
+            t.Errorf("wave %d has %d bosses, but there should only be a boss every 5th wave", wave, bosses)
+        }
+        if (definition.HealthIncrease == 1) != (wave%2 == 1) {
+            t.Errorf("wave %d increases the enemies' health by %d", wave, definition.HealthIncrease)
+        }
+        if (definition.BountyIncrease == 1) != (wave%4 == 1) {
+            t.Errorf("wave %d increases the enemies' bounty by %d", wave, definition.BountyIncrease)
+        }
+        if (definition.SpeedMultiplier != waves.Waves[last-2].SpeedMultiplier) || (definition.HealthMultiplier != waves.Waves[last-2].HealthMultiplier) {
+            t.Errorf("wave %d doesn't keep the multipliers of the last wave without a boss", wave)
+        }
+    }
+
+    // NOTE: Cut off part way through, the set should carry on close to what the rest of the file has
+    truncated := &WaveSet { Version: waveSetVersion, Waves: waves.Waves[:22] }
+    for wave := 23; wave <= last; wave++ {
+        expectedBosses, expected := waveEnemies(waves.Wave(wave))
+        bosses, others := waveEnemies(truncated.Wave(wave))
+        if (bosses != expectedBosses) || (math.Abs(float64(others - expected)) > 4.0) ||
+           (truncated.Wave(wave).Label != waves.Wave(wave).Label) {
+            t.Errorf("wave %d was extrapolated as %d enemies and %d bosses, but the file has %d and %d",
+                     wave, others, bosses, expected, expectedBosses)
+        }
+    }
+}